@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
+)
+
+const defaultAgentIterations = 10
+
+// trustedTool is implemented by tools that may run without confirmation,
+// such as those of an MCP server marked trust in the config.
+type trustedTool interface {
+	Trusted() bool
+}
+
+// runAgent answers prompt with the model calling local tools in a loop
+// until it stops asking for them or the iteration cap is reached.
+func runAgent(prompt, model string, cfg config.Config) error {
+	providerName := cfg.ResolvedProvider()
+	p, err := ask.Get(providerName)
+	if err != nil {
+		return err
+	}
+	if tp, ok := p.(ask.ToolCallingProvider); !ok || !tp.AcceptsTools() {
+		return fmt.Errorf("--agent is not supported by %s", providerName)
+	}
+	apiKey, err := cfg.RequireAPIKey(p)
+	if err != nil {
+		return err
+	}
+	if model == "" {
+		model = p.DefaultModel()
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+	tools := agentTools(root, cfg.Agent.AllowedCommands)
+	maxIter := maxToolTurns(cfg)
+
+	if dryRun {
+		var names []string
+		for _, t := range tools {
+			names = append(names, t.Name())
+		}
+		for _, name := range sortedKeys(cfg.MCPServers) {
+			names = append(names, "mcp__"+name+"__*")
+		}
+		fmt.Printf("[%s] model=%s agent tools=%s max_iterations=%d root=%s prompt=%q\n",
+			providerName, p.ResolveModel(model), strings.Join(names, ","), maxIter, root, prompt)
+		return nil
+	}
+
+	mcp := connectMCPServers(context.TODO(), cfg.MCPServers)
+	defer mcp.close()
+
+	req := ask.UserRequest(prompt)
+	req.Model = model
+	req.APIKey = apiKey
+	req.BaseURL = cfg.BaseURL
+	req.Features = ask.FeatureFlags{Thinking: thinkFlag, WebSearch: searchFlag}
+	req.Cache = promptCache
+	req.Messages[0].CachePrefix = pipePrefixLen(prompt)
+	req.Tools = append(tools, mcp.tools...)
+	return runToolLoop(p, req, maxIter, cfg.Agent.AutoApprove)
+}
+
+// maxToolTurns returns the configured cap on model turns in a tool loop.
+func maxToolTurns(cfg config.Config) int {
+	if cfg.Agent.MaxIterations > 0 {
+		return cfg.Agent.MaxIterations
+	}
+	return defaultAgentIterations
+}
+
+// runToolLoop streams responses to req, running the tool calls in each
+// and sending back their results, until the model answers without calling
+// a tool or maxIter turns have passed.
+func runToolLoop(p ask.Provider, req ask.Request, maxIter int, autoApprove bool) error {
+	approver := newToolApprover(autoApprove)
+	defer approver.close()
+
+	for range maxIter {
+		res, err := renderResponse(p.Run(context.TODO(), req), req.Features.Thinking)
+		if err != nil {
+			return err
+		}
+		if len(res.ToolCalls) == 0 {
+			return nil
+		}
+
+		req.Messages = append(req.Messages, ask.Message{Role: "assistant", Content: res.Text, ToolCalls: res.ToolCalls})
+		var results []ask.ToolResult
+		for _, c := range res.ToolCalls {
+			results = append(results, runToolCall(context.TODO(), req.Tools, c, approver))
+		}
+		req.Messages = append(req.Messages, ask.Message{Role: "user", ToolResults: results})
+	}
+	return fmt.Errorf("stopped after %d tool-calling turns (raise agent.max_iterations in config)", maxIter)
+}
+
+// runToolCall confirms and runs one tool call, turning failures and
+// refusals into error results the model can react to.
+func runToolCall(ctx context.Context, tools []ask.Tool, c ask.ToolCall, approver *toolApprover) ask.ToolResult {
+	result := ask.ToolResult{CallID: c.ID, Name: c.Name}
+	summary := toolCallSummary(c)
+	fmt.Fprintf(os.Stderr, "⏺ %s\n", summary)
+
+	var tool ask.Tool
+	for _, t := range tools {
+		if t.Name() == c.Name {
+			tool = t
+		}
+	}
+	if tool == nil {
+		result.Content, result.IsError = "unknown tool "+c.Name, true
+		return result
+	}
+	if t, ok := tool.(trustedTool); (!ok || !t.Trusted()) && !approver.approve(summary) {
+		result.Content, result.IsError = "The user declined this tool call.", true
+		return result
+	}
+
+	out, err := tool.Run(ctx, c.Args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, wizardDim.Render("  ✗ "+err.Error()))
+		result.Content, result.IsError = err.Error(), true
+		return result
+	}
+	fmt.Fprintln(os.Stderr, wizardDim.Render(fmt.Sprintf("  ⎿ %d lines", strings.Count(strings.TrimRight(out, "\n"), "\n")+1)))
+	result.Content = out
+	return result
+}
+
+// toolCallSummary formats a call as `name(argument)` for the terminal.
+func toolCallSummary(c ask.ToolCall) string {
+	var in struct{ Command []string }
+	if json.Unmarshal(c.Args, &in) == nil && len(in.Command) > 0 {
+		return fmt.Sprintf("%s(%s)", c.Name, firstLine(formatCommand(in.Command[0], in.Command[1:]), 60))
+	}
+	return toolUseSummary(c.Name, c.Args)
+}
+
+// toolApprover asks on the terminal before each tool call.
+type toolApprover struct {
+	tty    *os.File
+	reader *bufio.Reader
+	all    bool // approve everything for the rest of the run
+}
+
+func newToolApprover(autoApprove bool) *toolApprover {
+	a := &toolApprover{all: autoApprove}
+	if !autoApprove {
+		if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+			a.tty, a.reader = tty, bufio.NewReader(tty)
+		}
+	}
+	return a
+}
+
+func (a *toolApprover) approve(summary string) bool {
+	if a.all {
+		return true
+	}
+	if a.tty == nil {
+		fmt.Fprintln(os.Stderr, "  No terminal to confirm the call; declined. Set agent.auto_approve in config to allow it.")
+		return false
+	}
+	fmt.Fprintf(a.tty, "  Run %s? [y]es / [n]o / [a]ll: ", summary)
+	answer, _ := a.reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "a", "all":
+		a.all = true
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *toolApprover) close() {
+	if a.tty != nil {
+		a.tty.Close()
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/laurensent/ask/pkg/config"
+)
+
+func TestRunAgent(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/big.txt", []byte(strings.Repeat("x", 1000)), 0644)
+	t.Chdir(dir)
+
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		if len(requests) == 1 {
+			fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"list_dir","arguments":""}}]}}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"path\":\".\"}"}}]}}]}`+"\n\n")
+		} else {
+			fmt.Fprint(w, `data: {"id":"2","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"content":"big.txt is largest."}}]}`+"\n\n")
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	c := config.Config{Mode: "api", Provider: "xai", APIKey: "key", BaseURL: srv.URL}
+	c.Agent.AutoApprove = true
+	if err := runAgent("which file is largest?", "", c); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if tools, _ := requests[0]["tools"].([]any); len(tools) != 4 {
+		t.Errorf("sent %d tools, want 4", len(tools))
+	}
+	messages, _ := requests[1]["messages"].([]any)
+	if len(messages) != 3 {
+		t.Fatalf("second request has %d messages, want 3", len(messages))
+	}
+	result, _ := messages[2].(map[string]any)
+	if result["role"] != "tool" || result["tool_call_id"] != "call_1" || !strings.Contains(fmt.Sprint(result["content"]), "big.txt\t1000") {
+		t.Errorf("tool result message = %v", result)
+	}
+}
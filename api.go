@@ -4,22 +4,25 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/cache"
+	"github.com/laurensent/ask/pkg/config"
 )
 
 // runAPI resolves the provider, API key, and model, then delegates to the provider.
-func runAPI(prompt, model string, cfg appConfig) error {
-	providerName := cfg.resolvedProvider()
-	p, err := getProvider(providerName)
+func runAPI(prompt, model string, cfg config.Config) error {
+	providerName := cfg.ResolvedProvider()
+	p, err := ask.Get(providerName)
 	if err != nil {
 		return err
 	}
 
-	apiKey := os.Getenv(p.EnvKey())
-	if apiKey == "" {
-		apiKey = cfg.APIKey
-	}
-	if apiKey == "" && p.EnvKey() != "" {
-		return fmt.Errorf("API mode requires an API key. Set %q env var or \"api_key\" in config.", p.EnvKey())
+	apiKey, err := cfg.RequireAPIKey(p)
+	if err != nil {
+		return err
 	}
 
 	if model == "" {
@@ -27,12 +30,113 @@ func runAPI(prompt, model string, cfg appConfig) error {
 	}
 	modelID := p.ResolveModel(model)
 
-	features := FeatureFlags{Thinking: thinkFlag, WebSearch: searchFlag}
+	features := ask.FeatureFlags{Thinking: thinkFlag, WebSearch: searchFlag || sourcesOnly}
+
+	if len(fileFlags) > 0 {
+		if f, ok := p.(ask.FileInputProvider); !ok || !f.AcceptsFiles(model) {
+			return fmt.Errorf("--file is not supported by %s model %s (use an openai model such as gpt4o or o4-mini)", providerName, modelID)
+		}
+	}
 
 	if dryRun {
-		fmt.Printf("[%s] model=%s thinking=%v search=%v prompt=%q\n", providerName, modelID, features.Thinking, features.WebSearch, prompt)
+		fmt.Printf("[%s] model=%s thinking=%v search=%v prompt=%q", providerName, modelID, features.Thinking, features.WebSearch, prompt)
+		if len(fileFlags) > 0 {
+			fmt.Printf(" files=%q", fileFlags)
+		}
+		if len(cfg.MCPServers) > 0 {
+			fmt.Printf(" mcp=%s", strings.Join(sortedKeys(cfg.MCPServers), ","))
+		}
+		fmt.Println()
 		return nil
 	}
 
-	return p.Run(context.TODO(), prompt, model, apiKey, cfg.BaseURL, features)
+	req := ask.UserRequest(prompt)
+	req.Model = model
+	req.APIKey = apiKey
+	req.BaseURL = cfg.BaseURL
+	req.Features = features
+	req.Files = fileFlags
+	req.Cache = promptCache
+	req.Messages[0].CachePrefix = pipePrefixLen(prompt)
+
+	if sourcesOnly {
+		return printSources(p, req)
+	}
+
+	if len(cfg.MCPServers) > 0 {
+		if tp, ok := p.(ask.ToolCallingProvider); ok && tp.AcceptsTools() {
+			mcp := connectMCPServers(context.TODO(), cfg.MCPServers)
+			defer mcp.close()
+			if len(mcp.tools) > 0 {
+				req.Tools = mcp.tools
+				return runToolLoop(p, req, maxToolTurns(cfg), cfg.Agent.AutoApprove)
+			}
+		}
+	}
+
+	// Responses are cached only when the prompt is the whole input.
+	var key string
+	if cacheTTL > 0 && !noCache && len(req.Files) == 0 {
+		key = cache.Key(p.Name(), modelID, req, cacheParams(cfg, p.Name()))
+		if e, ok := cache.Get(key, cacheTTL); ok {
+			age := time.Since(e.Created).Round(time.Second)
+			fmt.Fprintln(os.Stderr, wizardDim.Render(fmt.Sprintf("cached %s ago · --no-cache to ask again", age)))
+			_, err := renderResponse(e.Replay(), req.Features.Thinking)
+			return err
+		}
+	}
+
+	stream := p.Run(context.TODO(), req)
+	res, err := renderResponse(stream, req.Features.Thinking)
+	if offerOllamaPull(err, p, model, cfg.BaseURL) {
+		res, err = renderResponse(stream, req.Features.Thinking)
+	}
+	if err == nil {
+		printCacheUsage(res.Usage)
+	}
+	if err == nil && key != "" {
+		e := cache.Entry{Provider: p.Name(), Model: modelID, Text: res.Text, Reasoning: res.Reasoning, Sources: res.Sources, Usage: res.Usage, Stop: res.StopReason}
+		if err := cache.Put(key, e); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: caching response: %v\n", err)
+		}
+	}
+	return err
+}
+
+// cacheParams returns the configured request options that change the
+// answers of provider, so that changing them misses the cache.
+func cacheParams(cfg config.Config, provider string) any {
+	if provider == "ollama" {
+		return struct {
+			NumCtx  int
+			Options map[string]any
+		}{cfg.Ollama.NumCtx, cfg.Ollama.Options}
+	}
+	return cfg.ProviderOptions[provider]
+}
+
+// printCacheUsage notes on stderr how much of the input was read from or
+// written to the provider's prompt cache.
+func printCacheUsage(u ask.Usage) {
+	if u.CacheReadTokens == 0 && u.CacheWriteTokens == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, wizardDim.Render(fmt.Sprintf("prompt cache: %d tokens read, %d written", u.CacheReadTokens, u.CacheWriteTokens)))
+}
+
+// printSources runs req for --sources-only, printing just the cited URLs.
+func printSources(p ask.Provider, req ask.Request) error {
+	sp := startSpinner()
+	res, err := ask.Collect(p.Run(context.TODO(), req), nil)
+	sp.Stop()
+	if err != nil {
+		return err
+	}
+	if len(res.Sources) == 0 {
+		return fmt.Errorf("no sources returned (the model answered without searching)")
+	}
+	for _, s := range res.Sources {
+		fmt.Println(s.URL)
+	}
+	return nil
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/laurensent/ask/pkg/config"
+)
+
+// cliBackend describes how to run an agentic CLI in single-shot mode.
+type cliBackend config.CLIBackend
+
+// builtinBackends are the CLIs ask knows how to drive without configuration.
+var builtinBackends = map[string]cliBackend{
+	"claude": {
+		Binary:       "claude",
+		PromptFlag:   "-p",
+		ModelFlag:    "--model",
+		ResumeFlag:   "--resume",
+		OutputFormat: "stream-json",
+		InstallURL:   "https://docs.anthropic.com/en/docs/claude-code",
+	},
+	"gemini": {
+		Binary:     "gemini",
+		PromptFlag: "-p",
+		ModelFlag:  "-m",
+		InstallURL: "https://github.com/google-gemini/gemini-cli",
+	},
+	"codex": {
+		Binary:     "codex",
+		Args:       []string{"exec"},
+		ModelFlag:  "-m",
+		InstallURL: "https://github.com/openai/codex",
+	},
+	"llm": {
+		Binary:     "llm",
+		ModelFlag:  "-m",
+		InstallURL: "https://llm.datasette.io",
+	},
+}
+
+// backendNames returns the sorted names of built-in and configured backends.
+func backendNames(c config.Config) []string {
+	seen := map[string]bool{}
+	var names []string
+	for name := range builtinBackends {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range c.CLIBackends {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectBackend returns the CLI backend selected by cli_backend, defaulting to claude.
+// Fields left empty in a config entry fall back to the built-in of the same name.
+func selectBackend(c config.Config) (string, cliBackend, error) {
+	name := c.CLIBackend
+	if name == "" {
+		name = "claude"
+	}
+	b, builtin := builtinBackends[name]
+	custom, configured := c.CLIBackends[name]
+	if !builtin && !configured {
+		return "", cliBackend{}, fmt.Errorf("unknown cli_backend %q (available: %s)", name, strings.Join(backendNames(c), ", "))
+	}
+	if configured {
+		b = mergeBackend(b, cliBackend(custom))
+	}
+	if b.Binary == "" {
+		b.Binary = name
+	}
+	return name, b, nil
+}
+
+// mergeBackend overlays the non-empty fields of o onto b.
+func mergeBackend(b, o cliBackend) cliBackend {
+	if o.Binary != "" {
+		b.Binary = o.Binary
+	}
+	if o.Args != nil {
+		b.Args = o.Args
+	}
+	if o.PromptFlag != "" {
+		b.PromptFlag = o.PromptFlag
+	}
+	if o.ModelFlag != "" {
+		b.ModelFlag = o.ModelFlag
+	}
+	if o.ResumeFlag != "" {
+		b.ResumeFlag = o.ResumeFlag
+	}
+	if o.OutputFormat != "" {
+		b.OutputFormat = o.OutputFormat
+	}
+	if o.InstallURL != "" {
+		b.InstallURL = o.InstallURL
+	}
+	return b
+}
+
+// streams reports whether output is parsed as stream-json events. A user
+// --output-format in extra turns it off so their format is printed as is.
+func (b cliBackend) streams(extra []string) bool {
+	return b.OutputFormat == "stream-json" && !hasOutputFormat(extra)
+}
+
+// commandArgs builds the argument list (without the binary) for prompt.
+// extra holds passthrough flags from the ask command line.
+func (b cliBackend) commandArgs(prompt, model, resume string, extra []string) []string {
+	args := append([]string{}, b.Args...)
+	if b.PromptFlag != "" {
+		args = append(args, b.PromptFlag, prompt)
+	}
+	if model != "" && b.ModelFlag != "" {
+		args = append(args, b.ModelFlag, model)
+	}
+	if resume != "" && b.ResumeFlag != "" {
+		args = append(args, b.ResumeFlag, resume)
+	}
+	if b.streams(extra) {
+		args = append(args, claudeStreamArgs...)
+	}
+	args = append(args, extra...)
+	if b.PromptFlag == "" {
+		args = append(args, prompt)
+	}
+	return args
+}
+
+// formatCommand renders a command line, quoting arguments the shell would split.
+func formatCommand(binary string, args []string) string {
+	parts := []string{binary}
+	for _, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n'\"\\$`!*?&|;<>()[]{}#~") {
+			a = strconv.Quote(a)
+		}
+		parts = append(parts, a)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/laurensent/ask/pkg/config"
+)
+
+func TestCommandArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		model   string
+		resume  string
+		extra   []string
+		want    []string
+	}{
+		{"claude", "claude", "opus", "", nil,
+			[]string{"-p", "hi", "--model", "opus", "--output-format", "stream-json", "--verbose", "--include-partial-messages"}},
+		{"claude resume", "claude", "", "abc", nil,
+			[]string{"-p", "hi", "--resume", "abc", "--output-format", "stream-json", "--verbose", "--include-partial-messages"}},
+		{"claude own output format", "claude", "", "", []string{"--output-format", "json"},
+			[]string{"-p", "hi", "--output-format", "json"}},
+		{"gemini", "gemini", "gemini-2.5-pro", "", nil,
+			[]string{"-p", "hi", "-m", "gemini-2.5-pro"}},
+		{"codex positional prompt", "codex", "", "", []string{"--full-auto"},
+			[]string{"exec", "--full-auto", "hi"}},
+		{"llm ignores resume", "llm", "gpt-4o", "abc", nil,
+			[]string{"-m", "gpt-4o", "hi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := builtinBackends[tt.backend].commandArgs("hi", tt.model, tt.resume, tt.extra)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commandArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigBackend(t *testing.T) {
+	c := config.Config{
+		CLIBackend: "claude",
+		CLIBackends: map[string]config.CLIBackend{
+			"claude": {Binary: "/opt/claude/bin/claude"},
+			"aider":  {PromptFlag: "--message"},
+		},
+	}
+
+	_, b, err := selectBackend(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Binary != "/opt/claude/bin/claude" || b.PromptFlag != "-p" || b.OutputFormat != "stream-json" {
+		t.Errorf("override not merged with built-in: %+v", b)
+	}
+
+	c.CLIBackend = "aider"
+	if _, b, err = selectBackend(c); err != nil || b.Binary != "aider" || b.PromptFlag != "--message" {
+		t.Errorf("custom backend = %+v, %v", b, err)
+	}
+
+	c.CLIBackend = "nope"
+	if _, _, err := selectBackend(c); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	got := formatCommand("claude", []string{"-p", "what's up?", "--model", "opus"})
+	want := `claude -p "what's up?" --model opus`
+	if got != want {
+		t.Errorf("formatCommand() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,618 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/spf13/cobra"
+)
+
+var batchOutput string
+var batchConcurrency int
+var batchRPM int
+var batchAsync bool
+var batchSystem string
+
+var batchCmd = &cobra.Command{
+	Use:   "batch FILE",
+	Short: "Run many prompts from a JSONL or line-delimited file",
+	Long: `Run every prompt in FILE and write one JSON result per line.
+
+FILE is either JSONL with objects like
+  {"id": "a1", "prompt": "...", "model": "haiku", "provider": "anthropic", "system": "..."}
+or plain text with one prompt per line (IDs are line numbers). Use - for stdin.
+
+Prompts whose ID already has a successful result in the output file are
+skipped, so an interrupted run can be resumed by re-running the command.
+With --async, prompts are submitted to the provider's batch API (Anthropic
+Message Batches or OpenAI Batch), which is cheaper but can take hours.
+Submitted batch IDs are saved next to the output file, so re-running an
+interrupted --async run waits for the same batches again.`,
+	Example: `  ask batch prompts.jsonl -o results.jsonl
+  ask batch lines.txt -o out.jsonl -m haiku -j 8 --rpm 50
+  ask batch prompts.jsonl -o results.jsonl --async`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBatch(args[0])
+	},
+}
+
+func init() {
+	batchCmd.Flags().StringVarP(&batchOutput, "output", "o", "", "JSONL output file (appended to; default stdout)")
+	batchCmd.Flags().IntVarP(&batchConcurrency, "concurrency", "j", 4, "number of prompts to run at once")
+	batchCmd.Flags().IntVar(&batchRPM, "rpm", 0, "max requests per minute per provider (0 = unlimited)")
+	batchCmd.Flags().BoolVar(&batchAsync, "async", false, "use the provider's asynchronous batch API")
+	batchCmd.Flags().StringVar(&batchSystem, "system", "", "system prompt for items that don't set one")
+}
+
+// batchItem is one prompt in a batch input file.
+type batchItem struct {
+	ID       string `json:"id"`
+	Prompt   string `json:"prompt"`
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	System   string `json:"system,omitempty"`
+}
+
+// batchResult is one line of batch output.
+type batchResult struct {
+	ID           string `json:"id"`
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+	Response     string `json:"response"`
+	InputTokens  int64  `json:"input_tokens,omitempty"`
+	OutputTokens int64  `json:"output_tokens,omitempty"`
+	CacheRead    int64  `json:"cache_read_tokens,omitempty"`
+	CacheWrite   int64  `json:"cache_write_tokens,omitempty"`
+	LatencyMS    int64  `json:"latency_ms,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// readBatchItems parses JSONL or line-delimited prompts. Blank lines are skipped.
+func readBatchItems(r io.Reader) ([]batchItem, error) {
+	var items []batchItem
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		item := batchItem{Prompt: line}
+		if strings.HasPrefix(line, "{") {
+			item = batchItem{}
+			if err := json.Unmarshal([]byte(line), &item); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			if item.Prompt == "" {
+				return nil, fmt.Errorf("line %d: missing \"prompt\"", n)
+			}
+		}
+		if item.ID == "" {
+			item.ID = strconv.Itoa(n)
+		}
+		if seen[item.ID] {
+			return nil, fmt.Errorf("line %d: duplicate id %q", n, item.ID)
+		}
+		seen[item.ID] = true
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// completedBatchIDs returns the IDs with a successful result in an existing output file.
+func completedBatchIDs(path string) (map[string]bool, error) {
+	done := map[string]bool{}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return done, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r batchResult
+		if json.Unmarshal(scanner.Bytes(), &r) == nil && r.Error == "" {
+			done[r.ID] = true
+		}
+	}
+	return done, scanner.Err()
+}
+
+// batchJob is a batch item resolved to a provider request.
+type batchJob struct {
+	item     batchItem
+	provider ask.Provider
+	req      ask.Request
+}
+
+func newBatchJobs(items []batchItem) ([]*batchJob, error) {
+	var jobs []*batchJob
+	for _, item := range items {
+		t := compareTarget{Provider: cfg.ResolvedProvider(), Model: model}
+		switch {
+		case item.Provider != "":
+			t = compareTarget{Provider: item.Provider, Model: item.Model}
+		case item.Model != "":
+			var err error
+			if t, err = parseTarget(item.Model, cfg.ResolvedProvider()); err != nil {
+				return nil, fmt.Errorf("item %s: %w", item.ID, err)
+			}
+		}
+
+		p, err := ask.Get(t.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("item %s: %w", item.ID, err)
+		}
+		pcfg := cfg.ForProvider(p.Name())
+		apiKey, err := pcfg.RequireAPIKey(p)
+		if err != nil {
+			return nil, fmt.Errorf("item %s: %w", item.ID, err)
+		}
+		if t.Model == "" {
+			t.Model = p.DefaultModel()
+		}
+
+		req := ask.UserRequest(item.Prompt)
+		req.System = item.System
+		if req.System == "" {
+			req.System = batchSystem
+		}
+		req.Model = t.Model
+		req.APIKey = apiKey
+		req.BaseURL = pcfg.BaseURL
+		req.Features = ask.FeatureFlags{Thinking: thinkFlag, WebSearch: searchFlag}
+		req.Cache = promptCache
+		jobs = append(jobs, &batchJob{item: item, provider: p, req: req})
+	}
+	return jobs, nil
+}
+
+func (j *batchJob) result(text string, usage ask.Usage, latency time.Duration, err error) batchResult {
+	r := batchResult{
+		ID:           j.item.ID,
+		Provider:     j.provider.Name(),
+		Model:        j.provider.ResolveModel(j.req.Model),
+		Response:     text,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		CacheRead:    usage.CacheReadTokens,
+		CacheWrite:   usage.CacheWriteTokens,
+		LatencyMS:    latency.Milliseconds(),
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// batchWriter appends results as JSON lines, safe for concurrent use.
+type batchWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (b *batchWriter) write(r batchResult) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, err = b.w.Write(append(data, '\n'))
+	return err
+}
+
+// rateLimiter spaces out calls to at most rpm per minute.
+type rateLimiter struct {
+	mu       sync.Mutex
+	next     time.Time
+	interval time.Duration
+}
+
+func newRateLimiter(rpm int) *rateLimiter {
+	if rpm <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Minute / time.Duration(rpm)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(at)):
+		return nil
+	}
+}
+
+// batchProgress draws a progress bar on stderr when it is a terminal.
+type batchProgress struct {
+	mu      sync.Mutex
+	bar     progress.Model
+	total   int
+	done    int
+	failed  int
+	start   time.Time
+	enabled bool
+}
+
+func newBatchProgress(total int) *batchProgress {
+	return &batchProgress{
+		bar:     progress.New(progress.WithSolidFill("#E36C38"), progress.WithWidth(30)),
+		total:   total,
+		start:   time.Now(),
+		enabled: isStderrTerminal(),
+	}
+}
+
+// add records a finished prompt and redraws.
+func (p *batchProgress) add(failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if failed {
+		p.failed++
+	}
+	p.draw()
+}
+
+// set updates the completed count (used while polling batch APIs) and redraws.
+func (p *batchProgress) set(done int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done = done
+	p.draw()
+}
+
+func (p *batchProgress) draw() {
+	if !p.enabled || p.total == 0 {
+		return
+	}
+	pct := float64(p.done) / float64(p.total)
+	line := fmt.Sprintf("%s  %d/%d", p.bar.ViewAs(pct), p.done, p.total)
+	if p.failed > 0 {
+		line += fmt.Sprintf("  %d failed", p.failed)
+	}
+	line += "  " + time.Since(p.start).Round(time.Second).String()
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+}
+
+func (p *batchProgress) finish() {
+	if p.enabled {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// runBatch runs all prompts in path that don't already have a result.
+func runBatch(path string) error {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	items, err := readBatchItems(in)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var out io.Writer = os.Stdout
+	if batchOutput != "" {
+		done, err := completedBatchIDs(batchOutput)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", batchOutput, err)
+		}
+		pending := items[:0]
+		for _, item := range items {
+			if !done[item.ID] {
+				pending = append(pending, item)
+			}
+		}
+		if skipped := len(items) - len(pending); skipped > 0 {
+			fmt.Fprintf(os.Stderr, "Skipping %d prompts already in %s\n", skipped, batchOutput)
+		}
+		items = pending
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to do.")
+		return nil
+	}
+
+	jobs, err := newBatchJobs(items)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		for _, j := range jobs {
+			fmt.Printf("[%s] id=%s model=%s prompt=%q\n", j.provider.Name(), j.item.ID, j.provider.ResolveModel(j.req.Model), firstLine(j.item.Prompt, 60))
+		}
+		return nil
+	}
+
+	if batchOutput != "" {
+		f, err := os.OpenFile(batchOutput, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w := &batchWriter{w: out}
+	prog := newBatchProgress(len(jobs))
+	defer prog.finish()
+
+	var failed int
+	if batchAsync {
+		failed, err = runBatchAsync(context.Background(), jobs, w, prog, batchStatePath(batchOutput))
+	} else {
+		failed, err = runBatchSync(context.Background(), jobs, w, prog)
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d prompts failed (re-run to retry them)", failed, len(jobs))
+	}
+	return nil
+}
+
+// runBatchSync runs jobs with a bounded worker pool and per-provider rate limits.
+func runBatchSync(ctx context.Context, jobs []*batchJob, w *batchWriter, prog *batchProgress) (int, error) {
+	limiters := map[string]*rateLimiter{}
+	for _, j := range jobs {
+		if limiters[j.provider.Name()] == nil {
+			limiters[j.provider.Name()] = newRateLimiter(batchRPM)
+		}
+	}
+
+	queue := make(chan *batchJob)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed int
+	var writeErr error
+
+	for range max(batchConcurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				var res ask.Result
+				err := limiters[j.provider.Name()].wait(ctx)
+				start := time.Now()
+				if err == nil {
+					res, err = ask.Collect(j.provider.Run(ctx, j.req), nil)
+				}
+				r := j.result(res.Text, res.Usage, time.Since(start), err)
+
+				mu.Lock()
+				if err != nil {
+					failed++
+				}
+				if werr := w.write(r); werr != nil && writeErr == nil {
+					writeErr = werr
+				}
+				mu.Unlock()
+				prog.add(err != nil)
+			}
+		}()
+	}
+
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+	return failed, writeErr
+}
+
+// runBatchAsync submits jobs to provider batch APIs, one batch per provider.
+// Submitted batches are recorded in statePath (if set) until their results
+// are written, so an interrupted run picks them up again instead of paying
+// for a new batch.
+func runBatchAsync(ctx context.Context, jobs []*batchJob, w *batchWriter, prog *batchProgress, statePath string) (int, error) {
+	for _, j := range jobs {
+		if _, ok := j.provider.(ask.BatchProvider); !ok {
+			return 0, fmt.Errorf("%s does not support --async batch processing", j.provider.Name())
+		}
+	}
+	state, err := loadBatchState(statePath)
+	if err != nil {
+		return 0, fmt.Errorf("reading %s: %w", statePath, err)
+	}
+
+	// Saved batches come first, then one new batch per provider for the rest.
+	byID := map[string]*batchJob{}
+	for _, j := range jobs {
+		byID[j.item.ID] = j
+	}
+	var runs []savedBatch
+	groups := map[string][]*batchJob{}
+	for _, sb := range state {
+		group := make([]*batchJob, 0, len(sb.Items))
+		for _, id := range sb.Items {
+			if j := byID[id]; j != nil && j.provider.Name() == sb.Provider {
+				group = append(group, j)
+			}
+		}
+		if len(group) != len(sb.Items) {
+			if len(group) > 0 {
+				fmt.Fprintf(os.Stderr, "Not resuming %s batch %s: its prompts changed\n", sb.Provider, sb.ID)
+			}
+			continue
+		}
+		for _, j := range group {
+			delete(byID, j.item.ID)
+		}
+		runs = append(runs, sb)
+		groups[sb.ID] = group
+	}
+	state = slices.Clone(runs)
+	var order []string
+	for _, j := range jobs {
+		if byID[j.item.ID] == nil {
+			continue
+		}
+		name := j.provider.Name()
+		if groups["new:"+name] == nil {
+			order = append(order, name)
+		}
+		groups["new:"+name] = append(groups["new:"+name], j)
+	}
+	for _, name := range order {
+		runs = append(runs, savedBatch{Provider: name})
+	}
+	if err := saveBatchState(statePath, state); err != nil {
+		return 0, err
+	}
+
+	var failed, base int
+	for _, run := range runs {
+		key := run.ID
+		if key == "" {
+			key = "new:" + run.Provider
+		}
+		group := groups[key]
+		reqs := make([]ask.Request, len(group))
+		for i, j := range group {
+			reqs[i] = j.req
+		}
+
+		start := time.Now()
+		offset := base
+		bp := group[0].provider.(ask.BatchProvider)
+		var responses []ask.BatchResponse
+		if run.ID != "" {
+			fmt.Fprintf(os.Stderr, "\r\033[KResuming %s batch %s (%d requests)\n", run.Provider, run.ID, len(reqs))
+			responses, err = bp.ResumeBatch(ctx, run.ID, reqs, func(p ask.BatchProgress) {
+				prog.set(offset + p.Done)
+			})
+			if err != nil && ctx.Err() == nil {
+				err = fmt.Errorf("resuming %s batch %s: %w (delete %s to submit these prompts again)", run.Provider, run.ID, err, statePath)
+			}
+		} else {
+			responses, err = bp.RunBatch(ctx, reqs, func(p ask.BatchProgress) {
+				if p.ID != "" && run.ID == "" {
+					run.ID = p.ID
+					fmt.Fprintf(os.Stderr, "\r\033[KSubmitted %s batch %s (%d requests)\n", run.Provider, p.ID, len(reqs))
+					run.Items = make([]string, len(group))
+					for i, j := range group {
+						run.Items[i] = j.item.ID
+					}
+					state = append(state, run)
+					if err := saveBatchState(statePath, state); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					}
+				}
+				prog.set(offset + p.Done)
+			})
+		}
+		if err != nil {
+			return failed, err
+		}
+		for i, j := range group {
+			resp := responses[i]
+			if resp.Err == nil && resp.Text == "" {
+				resp.Err = fmt.Errorf("no result returned")
+			}
+			if resp.Err != nil {
+				failed++
+			}
+			if err := w.write(j.result(resp.Text, resp.Usage, time.Since(start), resp.Err)); err != nil {
+				return failed, err
+			}
+		}
+		state = slices.DeleteFunc(state, func(sb savedBatch) bool { return sb.ID == run.ID })
+		if err := saveBatchState(statePath, state); err != nil {
+			return failed, err
+		}
+		base += len(group)
+		prog.set(base)
+	}
+	return failed, nil
+}
+
+// savedBatch is a submitted batch whose results haven't been written yet.
+type savedBatch struct {
+	Provider string   `json:"provider"`
+	ID       string   `json:"id"`
+	Items    []string `json:"items"` // item IDs, in request order
+}
+
+// batchStatePath returns the file submitted --async batches are recorded
+// in, next to the output file, or "" when writing to stdout.
+func batchStatePath(output string) string {
+	if output == "" {
+		return ""
+	}
+	return output + ".batches"
+}
+
+func loadBatchState(path string) ([]savedBatch, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state []savedBatch
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// saveBatchState writes state to path, removing the file once it is empty.
+func saveBatchState(path string, state []savedBatch) error {
+	if path == "" {
+		return nil
+	}
+	if len(state) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("saving batch IDs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+func TestReadBatchItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []batchItem
+		wantErr bool
+	}{
+		{
+			"plain lines use line numbers",
+			"first prompt\n\nthird prompt\n",
+			[]batchItem{{ID: "1", Prompt: "first prompt"}, {ID: "3", Prompt: "third prompt"}},
+			false,
+		},
+		{
+			"jsonl with ids and models",
+			`{"id":"a","prompt":"label this","model":"haiku"}` + "\n" + `{"prompt":"no id"}`,
+			[]batchItem{{ID: "a", Prompt: "label this", Model: "haiku"}, {ID: "2", Prompt: "no id"}},
+			false,
+		},
+		{"missing prompt", `{"id":"a"}`, nil, true},
+		{"duplicate id", `{"id":"a","prompt":"x"}` + "\n" + `{"id":"a","prompt":"y"}`, nil, true},
+		{"invalid json", `{"id":`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readBatchItems(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readBatchItems() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readBatchItems() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletedBatchIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	os.WriteFile(path, []byte(`{"id":"a","response":"ok"}
+{"id":"b","error":"rate limited"}
+not json
+`), 0644)
+
+	got, err := completedBatchIDs(path)
+	if err != nil {
+		t.Fatalf("completedBatchIDs() error = %v", err)
+	}
+	want := map[string]bool{"a": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completedBatchIDs() = %v, want %v", got, want)
+	}
+
+	missing, err := completedBatchIDs(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil || len(missing) != 0 {
+		t.Errorf("completedBatchIDs(missing) = %v, %v; want empty, nil", missing, err)
+	}
+}
+
+// fakeBatchProvider submits batch_1, which fails to finish the first time
+// it is waited for.
+type fakeBatchProvider struct {
+	ask.Provider
+	calls *[]string
+}
+
+func (fakeBatchProvider) Name() string                     { return "fake" }
+func (fakeBatchProvider) ResolveModel(alias string) string { return alias }
+
+func (p fakeBatchProvider) RunBatch(ctx context.Context, reqs []ask.Request, progress func(ask.BatchProgress)) ([]ask.BatchResponse, error) {
+	*p.calls = append(*p.calls, "run")
+	progress(ask.BatchProgress{ID: "batch_1", Total: len(reqs)})
+	return nil, errors.New("interrupted")
+}
+
+func (p fakeBatchProvider) ResumeBatch(ctx context.Context, id string, reqs []ask.Request, progress func(ask.BatchProgress)) ([]ask.BatchResponse, error) {
+	*p.calls = append(*p.calls, "resume "+id)
+	out := make([]ask.BatchResponse, len(reqs))
+	for i, req := range reqs {
+		out[i].Text = "re: " + req.Messages[0].Content
+	}
+	return out, nil
+}
+
+func TestRunBatchAsyncResume(t *testing.T) {
+	dir := t.TempDir()
+	statePath := batchStatePath(filepath.Join(dir, "out.jsonl"))
+	var calls []string
+	p := fakeBatchProvider{calls: &calls}
+	var jobs []*batchJob
+	for _, id := range []string{"a", "b"} {
+		jobs = append(jobs, &batchJob{item: batchItem{ID: id, Prompt: id}, provider: p, req: ask.UserRequest(id)})
+	}
+
+	var out strings.Builder
+	w := &batchWriter{w: &out}
+	if _, err := runBatchAsync(context.Background(), jobs, w, &batchProgress{}, statePath); err == nil {
+		t.Fatal("interrupted batch returned no error")
+	}
+	if state, err := loadBatchState(statePath); err != nil || len(state) != 1 || state[0].ID != "batch_1" {
+		t.Fatalf("saved batches = %+v, %v", state, err)
+	}
+
+	failed, err := runBatchAsync(context.Background(), jobs, w, &batchProgress{}, statePath)
+	if err != nil || failed != 0 {
+		t.Fatalf("resumed run: %d failed, %v", failed, err)
+	}
+	if want := []string{"run", "resume batch_1"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if !strings.Contains(out.String(), `"response":"re: b"`) {
+		t.Errorf("output:\n%s", out.String())
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("%s left behind: %v", statePath, err)
+	}
+}
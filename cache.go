@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/laurensent/ask/pkg/cache"
+	"github.com/laurensent/ask/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the response cache",
+	Long: `API responses are cached when cache_ttl is set in the config or --cache-ttl
+is passed, so asking the same question of the same model with the same
+settings again is answered from disk. --no-cache skips the cache for one
+query.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the number and size of cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := cache.Stat()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Location: %s\n", cache.Dir())
+		fmt.Printf("Entries:  %d\n", s.Entries)
+		fmt.Printf("Size:     %.1f KB\n", float64(s.Bytes)/1024)
+		if s.Entries > 0 {
+			fmt.Printf("Oldest:   %s\n", s.Oldest.Format(history.TimeFormat))
+			fmt.Printf("Newest:   %s\n", s.Newest.Format(history.TimeFormat))
+		}
+		if cacheTTL > 0 {
+			fmt.Printf("TTL:      %s\n", cacheTTL)
+		} else {
+			fmt.Println("TTL:      off (set cache_ttl in the config to enable)")
+		}
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := cache.Clear()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d cached responses.\n", n)
+		return nil
+	},
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/cache"
+	"github.com/laurensent/ask/pkg/config"
+)
+
+func TestCLICache(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.json")
+	setReply := func(text string) {
+		if err := os.WriteFile(script, []byte(`[{"text": "`+text+`"}]`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := `{"mode": "api", "provider": "mock", "cache_ttl": "1h", "mock_script": "` + script + `"}`
+	env := []string{"XDG_CACHE_HOME=" + filepath.Join(dir, "cache")}
+	ask := func(args ...string) (string, string) {
+		t.Helper()
+		out, stderr, err := runAskEnv(t, config, dir, env, args...)
+		if err != nil {
+			t.Fatalf("ask %v: %v: %s", args, err, stderr)
+		}
+		return out, stderr
+	}
+
+	setReply("first answer")
+	if out, stderr := ask("what", "now?"); out != "first answer" || strings.Contains(stderr, "cached") {
+		t.Errorf("uncached run: out %q, stderr %q", out, stderr)
+	}
+	setReply("second answer")
+	if out, stderr := ask("what", "now?"); out != "first answer" || !strings.Contains(stderr, "cached") {
+		t.Errorf("cached run: out %q, stderr %q", out, stderr)
+	}
+	if out, _ := ask("-m", "other", "what", "now?"); out != "second answer" {
+		t.Errorf("another model was answered from the cache: %q", out)
+	}
+	if out, _ := ask("--no-cache", "what", "now?"); out != "second answer" {
+		t.Errorf("--no-cache run: %q", out)
+	}
+	if out, _ := ask("--cache-ttl", "1ns", "what", "now?"); out != "second answer" {
+		t.Errorf("expired entry was used: %q", out)
+	}
+
+	if out, _ := ask("cache", "stats"); !strings.Contains(out, "Entries:  2") || !strings.Contains(out, "TTL:      1h0m0s") {
+		t.Errorf("cache stats:\n%s", out)
+	}
+	if out, _ := ask("cache", "clear"); out != "Removed 2 cached responses.\n" {
+		t.Errorf("cache clear: %q", out)
+	}
+}
+
+func TestCacheParams(t *testing.T) {
+	req := ask.UserRequest("hi")
+	base := config.Config{Ollama: ask.OllamaOptions{Options: map[string]any{"temperature": 0.2}}}
+	warm := base
+	warm.Ollama = ask.OllamaOptions{Options: map[string]any{"temperature": 0.9}}
+	bigger := base
+	bigger.Ollama.NumCtx = 32768
+
+	key := cache.Key("ollama", "llama3", req, cacheParams(base, "ollama"))
+	for name, c := range map[string]config.Config{"temperature": warm, "num_ctx": bigger} {
+		if cache.Key("ollama", "llama3", req, cacheParams(c, "ollama")) == key {
+			t.Errorf("changing ollama %s doesn't change the key", name)
+		}
+	}
+	if cache.Key("openai", "gpt-4o", req, cacheParams(warm, "openai")) != cache.Key("openai", "gpt-4o", req, cacheParams(base, "openai")) {
+		t.Error("ollama options change the key for another provider")
+	}
+}
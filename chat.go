@@ -0,0 +1,515 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+var chatResume string
+var chatList bool
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Open a full-screen chat session",
+	Long: `Open a full-screen chat with the configured provider.
+
+Enter sends, Alt+Enter inserts a newline, Ctrl+E edits the input in $EDITOR,
+PgUp/PgDn scroll, Esc cancels a streaming reply or quits. Slash commands:
+
+  /model NAME      switch model
+  /provider NAME   switch provider
+  /system [TEXT]   set (or clear) the system prompt
+  /clear           start a new conversation
+  /save [FILE]     save the transcript as markdown
+  /think           toggle extended thinking
+  /search          toggle web search
+
+Sessions are saved automatically and can be resumed with --resume.`,
+	Example: `  ask chat
+  ask -i
+  ask chat --list
+  ask chat --resume last`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if chatList {
+			return listChatSessionsCmd()
+		}
+		return runChat(chatResume)
+	},
+}
+
+func init() {
+	chatCmd.Flags().StringVar(&chatResume, "resume", "", "resume a saved session by ID (or \"last\")")
+	chatCmd.Flags().BoolVar(&chatList, "list", false, "list saved chat sessions")
+}
+
+var (
+	chatUserLabel      = lipgloss.NewStyle().Bold(true)
+	chatAssistantLabel = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#E36C38"))
+	chatErrorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
+)
+
+// chatChunkMsg carries a streamed text chunk into the chat model.
+type chatChunkMsg string
+
+// chatDoneMsg signals the end of a streamed reply.
+type chatDoneMsg struct {
+	res ask.Result
+	err error
+}
+
+// chatModel is the bubbletea model for the full-screen chat REPL.
+type chatModel struct {
+	viewport viewport.Model
+	input    textarea.Model
+	session  *history.Session
+	provider ask.Provider
+	apiKey   string
+	baseURL  string
+	features ask.FeatureFlags
+
+	rendered  []string // rendered messages, parallel to session.Messages
+	partial   string
+	streaming bool
+	stream    chan tea.Msg
+	cancel    context.CancelFunc
+
+	status  string
+	isError bool
+	width   int
+	height  int
+	quit    bool
+}
+
+func newChatModel(session *history.Session, p ask.Provider, apiKey, baseURL string, features ask.FeatureFlags) chatModel {
+	ta := newPromptTextarea()
+	ta.Placeholder = "Ask anything (/help for commands)"
+	ta.SetHeight(3)
+	ta.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+
+	return chatModel{
+		viewport: viewport.New(80, 20),
+		input:    ta,
+		session:  session,
+		provider: p,
+		apiKey:   apiKey,
+		baseURL:  baseURL,
+		features: features,
+	}
+}
+
+func (m chatModel) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.input.SetWidth(msg.Width)
+		m.viewport.Width = msg.Width
+		m.viewport.Height = max(msg.Height-3-m.input.Height(), 1)
+		m.rendered = nil
+		m.refresh()
+		return m, nil
+
+	case chatChunkMsg:
+		m.partial += string(msg)
+		m.refresh()
+		return m, waitForChat(m.stream)
+
+	case chatDoneMsg:
+		m.finishStream(msg.res, msg.err)
+		m.refresh()
+		return m, nil
+
+	case editorDoneMsg:
+		if msg.err != nil {
+			m.setError(msg.err)
+		} else {
+			m.input.SetValue(msg.text)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			m.stopStream()
+			m.quit = true
+			return m, tea.Quit
+		case tea.KeyEsc:
+			if m.streaming {
+				m.stopStream()
+				return m, nil
+			}
+			m.quit = true
+			return m, tea.Quit
+		case tea.KeyCtrlE:
+			return m, editTextCmd(m.input.Value())
+		case tea.KeyPgUp:
+			m.viewport.PageUp()
+			return m, nil
+		case tea.KeyPgDown:
+			m.viewport.PageDown()
+			return m, nil
+		case tea.KeyEnter:
+			if msg.Alt {
+				break
+			}
+			return m.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// submit handles the current input: a slash command or a new user message.
+func (m chatModel) submit() (tea.Model, tea.Cmd) {
+	text := strings.TrimSpace(m.input.Value())
+	if text == "" || m.streaming {
+		return m, nil
+	}
+	m.input.Reset()
+
+	if name, arg, ok := parseSlashCommand(text); ok {
+		return m.runCommand(name, arg)
+	}
+
+	m.session.Messages = append(m.session.Messages, ask.Message{Role: "user", Content: text})
+	m.setStatus("")
+	cmd := m.startStream()
+	m.refresh()
+	return m, cmd
+}
+
+// parseSlashCommand splits "/name arg..." into its name and argument.
+func parseSlashCommand(text string) (name, arg string, ok bool) {
+	if !strings.HasPrefix(text, "/") || strings.HasPrefix(text, "//") {
+		return "", "", false
+	}
+	name, arg, _ = strings.Cut(text[1:], " ")
+	if name == "" {
+		return "", "", false
+	}
+	return strings.ToLower(name), strings.TrimSpace(arg), true
+}
+
+func (m chatModel) runCommand(name, arg string) (tea.Model, tea.Cmd) {
+	switch name {
+	case "model":
+		if arg == "" {
+			m.setStatus(fmt.Sprintf("model: %s (aliases: %s)", m.session.Model, strings.Join(m.provider.ModelAliases(), ", ")))
+			break
+		}
+		m.session.Model = arg
+		m.setStatus(fmt.Sprintf("model set to %s", m.provider.ResolveModel(arg)))
+	case "provider":
+		if arg == "" {
+			m.setStatus(fmt.Sprintf("provider: %s (available: %s)", m.provider.Name(), strings.Join(ask.Names(), ", ")))
+			break
+		}
+		p, err := ask.Get(arg)
+		if err != nil {
+			m.setError(err)
+			break
+		}
+		pcfg := cfg.ForProvider(p.Name())
+		apiKey, err := pcfg.RequireAPIKey(p)
+		if err != nil {
+			m.setError(err)
+			break
+		}
+		m.provider, m.apiKey, m.baseURL = p, apiKey, pcfg.BaseURL
+		m.session.Provider = p.Name()
+		m.session.Model = p.DefaultModel()
+		m.session.ResponseID = "" // belongs to the previous provider
+		m.setStatus(fmt.Sprintf("provider set to %s (model %s)", p.Name(), m.session.Model))
+	case "system":
+		m.session.System = arg
+		if arg == "" {
+			m.setStatus("system prompt cleared")
+		} else {
+			m.setStatus("system prompt set")
+		}
+	case "clear":
+		m.session = history.NewSession(m.provider.Name(), m.session.Model)
+		m.rendered = nil
+		m.setStatus("new conversation")
+	case "save":
+		path := arg
+		if path == "" {
+			path = "ask-chat-" + m.session.ID + ".md"
+		}
+		if err := os.WriteFile(path, []byte(m.session.Markdown()), 0644); err != nil {
+			m.setError(fmt.Errorf("failed to save transcript: %w", err))
+			break
+		}
+		m.setStatus("transcript saved to " + path)
+	case "think":
+		m.features.Thinking = !m.features.Thinking
+		m.setStatus(fmt.Sprintf("thinking %s", onOff(m.features.Thinking)))
+	case "search":
+		m.features.WebSearch = !m.features.WebSearch
+		m.setStatus(fmt.Sprintf("web search %s", onOff(m.features.WebSearch)))
+	case "help":
+		m.setStatus("/model /provider /system /clear /save /think /search /quit")
+	case "quit", "exit":
+		m.quit = true
+		return m, tea.Quit
+	default:
+		m.setError(fmt.Errorf("unknown command /%s (try /help)", name))
+	}
+	m.refresh()
+	return m, nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (m *chatModel) setStatus(s string) {
+	m.status, m.isError = s, false
+}
+
+func (m *chatModel) setError(err error) {
+	m.status, m.isError = err.Error(), true
+}
+
+// startStream runs the provider in the background, feeding chunks back as messages.
+func (m *chatModel) startStream() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.streaming = true
+	m.partial = ""
+
+	req := ask.Request{
+		Messages: append([]ask.Message(nil), m.session.Messages...),
+		System:   m.session.System,
+		Model:    m.session.Model,
+		APIKey:   m.apiKey,
+		BaseURL:  m.baseURL,
+		Features: m.features,
+
+		PreviousResponseID: m.session.ResponseID,
+	}
+	p := m.provider
+	ch := make(chan tea.Msg, 64)
+	m.stream = ch
+
+	go func() {
+		res, err := ask.Collect(p.Run(ctx, req), func(text string) {
+			ch <- chatChunkMsg(text)
+		})
+		ch <- chatDoneMsg{res: res, err: err}
+	}()
+	return waitForChat(ch)
+}
+
+func waitForChat(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-ch }
+}
+
+func (m *chatModel) stopStream() {
+	if m.streaming && m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// finishStream records the streamed reply and persists the session.
+func (m *chatModel) finishStream(res ask.Result, err error) {
+	m.streaming = false
+	m.cancel = nil
+	reply := m.partial
+	m.partial = ""
+
+	if err != nil {
+		if reply == "" {
+			// Nothing came back: drop the user turn and put it back in the input.
+			last := m.session.Messages[len(m.session.Messages)-1]
+			m.session.Messages = m.session.Messages[:len(m.session.Messages)-1]
+			m.input.SetValue(last.Content)
+		}
+		if errors.Is(err, context.Canceled) {
+			m.setStatus("canceled")
+		} else {
+			m.setError(err)
+		}
+		if reply == "" {
+			return
+		}
+	}
+
+	// Only a complete reply can be continued from server-side state.
+	m.session.ResponseID = ""
+	if err == nil {
+		reply += ask.Footnotes(res.Sources)
+		m.session.ResponseID = res.ResponseID
+	}
+	m.session.Messages = append(m.session.Messages, ask.Message{Role: "assistant", Content: reply})
+	if err := m.session.Save(); err != nil {
+		m.setError(fmt.Errorf("failed to save session: %w", err))
+	}
+}
+
+// refresh re-renders the transcript into the viewport.
+func (m *chatModel) refresh() {
+	if m.width == 0 {
+		return
+	}
+	atBottom := m.viewport.AtBottom()
+
+	if len(m.rendered) > len(m.session.Messages) {
+		m.rendered = nil
+	}
+	for i := len(m.rendered); i < len(m.session.Messages); i++ {
+		m.rendered = append(m.rendered, m.renderMessage(m.session.Messages[i]))
+	}
+
+	var b strings.Builder
+	for _, r := range m.rendered {
+		b.WriteString(r)
+	}
+	if m.streaming {
+		b.WriteString(chatAssistantLabel.Render(m.provider.ResolveModel(m.session.Model)) + "\n")
+		b.WriteString(lipgloss.NewStyle().Width(m.width).Render(m.partial) + "\n")
+	}
+	m.viewport.SetContent(b.String())
+	if atBottom || m.streaming {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m chatModel) renderMessage(msg ask.Message) string {
+	if msg.Role == "user" {
+		return chatUserLabel.Render("You") + "\n" +
+			lipgloss.NewStyle().Width(m.width).Render(msg.Content) + "\n\n"
+	}
+	body, err := renderMarkdownWidth(strings.TrimSpace(msg.Content), m.width)
+	if err != nil {
+		body = msg.Content
+	}
+	return chatAssistantLabel.Render("Assistant") + "\n" + body + "\n"
+}
+
+func (m chatModel) View() string {
+	if m.quit {
+		return ""
+	}
+
+	header := titleStyle.Render("ask chat") + wizardDim.Render(fmt.Sprintf("  %s · %s", m.provider.Name(), m.provider.ResolveModel(m.session.Model)))
+	if m.features.Thinking {
+		header += wizardDim.Render("  [think]")
+	}
+	if m.features.WebSearch {
+		header += wizardDim.Render("  [search]")
+	}
+
+	status := wizardDim.Render("Enter = send  Alt+Enter = newline  PgUp/PgDn = scroll  Esc = quit")
+	if m.streaming {
+		status = wizardDim.Render("Streaming... Esc = cancel")
+	} else if m.status != "" && m.isError {
+		status = chatErrorStyle.Render(m.status)
+	} else if m.status != "" {
+		status = wizardDim.Render(m.status)
+	}
+
+	return header + "\n" + m.viewport.View() + "\n" + status + "\n" + m.input.View()
+}
+
+// runChat opens the chat TUI, optionally resuming a saved session.
+func runChat(resume string) error {
+	if !isStdoutTerminal() || isPiped() {
+		return fmt.Errorf("chat requires an interactive terminal")
+	}
+
+	var session *history.Session
+	if resume != "" {
+		var err error
+		session, err = history.LoadSession(resume)
+		if err != nil {
+			return err
+		}
+	} else {
+		session = history.NewSession(cfg.ResolvedProvider(), model)
+	}
+
+	p, err := ask.Get(session.Provider)
+	if err != nil {
+		return err
+	}
+	pcfg := cfg.ForProvider(p.Name())
+	apiKey, err := pcfg.RequireAPIKey(p)
+	if err != nil {
+		return err
+	}
+	if session.Model == "" {
+		session.Model = p.DefaultModel()
+	}
+
+	m := newChatModel(session, p, apiKey, pcfg.BaseURL, ask.FeatureFlags{Thinking: thinkFlag, WebSearch: searchFlag})
+	prog := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+	result, err := prog.Run()
+	if err != nil {
+		return err
+	}
+
+	final := result.(chatModel)
+	saved, err := final.saveOnQuit()
+	if saved {
+		fmt.Fprintf(os.Stderr, "Session %s saved. Resume with: ask chat --resume %s\n", final.session.ID, final.session.ID)
+	}
+	return err
+}
+
+// saveOnQuit stops a reply in progress, keeping what arrived or dropping
+// the unanswered turn, and saves the session if it has any messages.
+func (m *chatModel) saveOnQuit() (bool, error) {
+	m.stopStream()
+	if m.streaming {
+		m.finishStream(ask.Result{}, context.Canceled)
+	}
+	if len(m.session.Messages) == 0 {
+		return false, nil
+	}
+	if err := m.session.Save(); err != nil {
+		return false, fmt.Errorf("failed to save session: %w", err)
+	}
+	return true, nil
+}
+
+// listChatSessionsCmd prints saved chat sessions.
+func listChatSessionsCmd() error {
+	sessions, err := history.ListSessions()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No saved chat sessions.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "ID\tUPDATED\tMODEL\tMESSAGES\tTITLE\n")
+	for _, s := range sessions {
+		fmt.Fprintf(w, "%s\t%s\t%s:%s\t%d\t%s\n", s.ID, s.Updated.Format(history.TimeFormat), s.Provider, s.Model, len(s.Messages), s.Title())
+	}
+	return w.Flush()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/history"
+)
+
+func TestParseSlashCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantName string
+		wantArg  string
+		wantOK   bool
+	}{
+		{"plain text", "hello", "", "", false},
+		{"command only", "/clear", "clear", "", true},
+		{"command with arg", "/model opus", "model", "opus", true},
+		{"arg with spaces", "/system be concise and direct", "system", "be concise and direct", true},
+		{"uppercase name", "/THINK", "think", "", true},
+		{"double slash is text", "//not a command", "", "", false},
+		{"bare slash", "/", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, arg, ok := parseSlashCommand(tt.input)
+			if name != tt.wantName || arg != tt.wantArg || ok != tt.wantOK {
+				t.Errorf("parseSlashCommand(%q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.input, name, arg, ok, tt.wantName, tt.wantArg, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestChatSaveOnQuit(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	tests := []struct {
+		name      string
+		partial   string
+		wantSaved bool
+	}{
+		{"quit before any reply", "", false},
+		{"quit mid-reply", "Partial ans", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := history.NewSession("mock", "mock")
+			session.Messages = []ask.Message{{Role: "user", Content: "hi"}}
+			m := chatModel{session: session, input: newPromptTextarea(), streaming: true, partial: tt.partial}
+			saved, err := m.saveOnQuit()
+			if err != nil || saved != tt.wantSaved {
+				t.Fatalf("saveOnQuit() = %v, %v; want %v", saved, err, tt.wantSaved)
+			}
+			if !saved {
+				return
+			}
+			loaded, err := history.LoadSession(session.ID)
+			if err != nil {
+				t.Fatalf("session %s can't be resumed: %v", session.ID, err)
+			}
+			if n := len(loaded.Messages); n != 2 || loaded.Messages[1].Content != tt.partial {
+				t.Errorf("saved messages = %+v", loaded.Messages)
+			}
+		})
+	}
+}
+
+func TestChatProviderSwitchDropsResponseID(t *testing.T) {
+	session := history.NewSession("openai", "gpt4o")
+	session.ResponseID = "resp_123"
+	m := chatModel{session: session, input: newPromptTextarea()}
+	m.runCommand("provider", "ollama")
+	if session.Provider != "ollama" {
+		t.Fatalf("provider = %q, want ollama", session.Provider)
+	}
+	if session.ResponseID != "" {
+		t.Errorf("ResponseID %q kept across the provider switch", session.ResponseID)
+	}
+}
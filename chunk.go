@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+const (
+	// defaultContextLimit applies to models missing from contextLimits.
+	defaultContextLimit = 32000
+	// outputReserve is the part of the context window kept free for the answer.
+	outputReserve = 16000
+	// chunkConcurrency bounds the number of chunk requests in flight.
+	chunkConcurrency = 4
+)
+
+// contextLimits maps model ID prefixes to context window sizes in tokens.
+// The longest matching prefix wins, so specific entries override families.
+var contextLimits = []struct {
+	prefix string
+	tokens int
+}{
+	{"claude-", 200000},
+	{"gpt-4o", 128000},
+	{"gpt-4.1", 1000000},
+	{"gpt-5", 400000},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"gemini-", 1000000},
+	{"grok-", 131072},
+	{"llama3", 8192},
+	{"qwen3", 32768},
+	{"deepseek-r1", 65536},
+	{"deepseek-chat", 128000},
+	{"deepseek-reasoner", 128000},
+	{"mistral-", 128000},
+	{"codestral-", 256000},
+	{"llama-3", 131072},
+}
+
+// contextLimit returns the context window for modelID. The context_limit
+// config setting overrides the built-in table.
+func contextLimit(modelID string) int {
+	if cfg.ContextLimit > 0 {
+		return cfg.ContextLimit
+	}
+	// Bedrock IDs carry a geo/vendor prefix: us.anthropic.claude-...
+	if _, id, ok := strings.Cut(modelID, "anthropic."); ok {
+		modelID = id
+	}
+	best, bestLen := defaultContextLimit, 0
+	for _, l := range contextLimits {
+		if strings.HasPrefix(modelID, l.prefix) && len(l.prefix) > bestLen {
+			best, bestLen = l.tokens, len(l.prefix)
+		}
+	}
+	return best
+}
+
+// inputBudget returns how many tokens of input fit in modelID's context.
+func inputBudget(modelID string) int {
+	limit := contextLimit(modelID)
+	return max(limit-min(outputReserve, limit/4), 1024)
+}
+
+// estimateTokens approximates the token count of s: about four ASCII
+// characters per token, and one token per non-ASCII rune (CJK, emoji).
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// splitChunks splits content into pieces of at most maxTokens, preferring
+// paragraph boundaries, then line boundaries, and only splitting inside a
+// line when a single line is too long.
+func splitChunks(content string, maxTokens int) []string {
+	var chunks []string
+	var cur strings.Builder
+	curTokens := 0
+
+	flush := func() {
+		if cur.Len() > 0 {
+			chunks = append(chunks, strings.TrimRight(cur.String(), "\n"))
+			cur.Reset()
+			curTokens = 0
+		}
+	}
+	add := func(piece string, sep string) {
+		t := estimateTokens(piece + sep)
+		if curTokens+t > maxTokens {
+			flush()
+		}
+		cur.WriteString(piece + sep)
+		curTokens += t
+	}
+
+	for _, para := range strings.Split(content, "\n\n") {
+		if estimateTokens(para) <= maxTokens {
+			add(para, "\n\n")
+			continue
+		}
+		for _, line := range strings.Split(para, "\n") {
+			if estimateTokens(line) <= maxTokens {
+				add(line, "\n")
+				continue
+			}
+			for _, piece := range splitRunes(line, maxTokens) {
+				add(piece, "")
+				flush()
+			}
+		}
+		add("", "\n")
+	}
+	flush()
+	return chunks
+}
+
+// splitRunes hard-splits a single long line into pieces of at most maxTokens.
+func splitRunes(s string, maxTokens int) []string {
+	var pieces []string
+	start, ascii, other := 0, 0, 0
+	for i, r := range s {
+		// Keep estimateTokens' count as we go rather than rescanning.
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+		if (ascii+3)/4+other >= maxTokens {
+			end := i + utf8.RuneLen(r)
+			pieces = append(pieces, s[start:end])
+			start, ascii, other = end, 0, 0
+		}
+	}
+	if start < len(s) {
+		pieces = append(pieces, s[start:])
+	}
+	return pieces
+}
+
+// truncateContent keeps the head or tail of content within maxTokens,
+// cutting on line boundaries and marking what was dropped.
+func truncateContent(content string, maxTokens int, keep string) string {
+	lines := strings.Split(content, "\n")
+	total, n := 0, 0
+	for i := range lines {
+		line := lines[i]
+		if keep == "tail" {
+			line = lines[len(lines)-1-i]
+		}
+		t := estimateTokens(line + "\n")
+		if total+t > maxTokens {
+			break
+		}
+		total += t
+		n++
+	}
+	dropped := len(lines) - n
+	if dropped == 0 {
+		return content
+	}
+	marker := fmt.Sprintf("[... %d lines truncated ...]", dropped)
+	if keep == "tail" {
+		return marker + "\n" + strings.Join(lines[len(lines)-n:], "\n")
+	}
+	return strings.Join(lines[:n], "\n") + "\n" + marker
+}
+
+// cliBackendProviders maps CLI backends to the provider whose models they run.
+var cliBackendProviders = map[string]string{
+	"claude": "anthropic",
+	"gemini": "gemini",
+	"codex":  "openai",
+}
+
+// activeModel returns the provider and resolved model ID a query will use.
+// In CLI mode the provider follows cli_backend; for backends without one
+// (llm, custom) it is nil and the model ID is -m as given.
+func activeModel() (ask.Provider, string, error) {
+	name := cfg.ResolvedProvider()
+	if cfg.Mode != "api" {
+		backend, _, err := selectBackend(cfg)
+		if err != nil {
+			return nil, "", err
+		}
+		if name = cliBackendProviders[backend]; name == "" {
+			return nil, model, nil
+		}
+	}
+	p, err := ask.Get(name)
+	if err != nil {
+		return nil, "", err
+	}
+	m := model
+	if m == "" {
+		m = p.DefaultModel()
+	}
+	return p, p.ResolveModel(m), nil
+}
+
+// fitPipeContent checks piped input against the model's context window. When
+// it doesn't fit, it truncates per --truncate, or asks on the terminal
+// whether to keep the head or tail, chunk, send anyway, or abort.
+// It returns the (possibly truncated) content and whether to chunk instead.
+func fitPipeContent(content string) (string, bool, error) {
+	_, modelID, err := activeModel()
+	if err != nil {
+		return "", false, err
+	}
+	budget := inputBudget(modelID)
+	tokens := estimateTokens(content)
+	if tokens <= budget {
+		return content, false, nil
+	}
+
+	if truncateMode == "head" || truncateMode == "tail" {
+		return truncateContent(content, budget, truncateMode), false, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: input is ~%d tokens, over the ~%d token budget for %s.\n", tokens, budget, modelID)
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Sending anyway. Use --chunk or --truncate head|tail to fit it.")
+		return content, false, nil
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, "[h]ead / [t]ail / [c]hunk / [s]end anyway / [a]bort? ")
+	answer, _ := bufio.NewReader(tty).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "h", "head":
+		return truncateContent(content, budget, "head"), false, nil
+	case "t", "tail":
+		return truncateContent(content, budget, "tail"), false, nil
+	case "c", "chunk":
+		return content, true, nil
+	case "s", "send":
+		return content, false, nil
+	default:
+		return "", false, errCanceled
+	}
+}
+
+// runChunked answers question over content that is too large for one request:
+// it asks the question of each chunk concurrently (map), then synthesizes the
+// partial answers into one (reduce), repeating the reduce step if needed.
+func runChunked(question, content string) error {
+	if cfg.Mode != "api" {
+		return fmt.Errorf("--chunk requires API mode (run: ask config)")
+	}
+	p, modelID, err := activeModel()
+	if err != nil {
+		return err
+	}
+	apiKey, err := cfg.RequireAPIKey(p)
+	if err != nil {
+		return err
+	}
+	if question == "" {
+		question = "Please analyze it."
+	}
+
+	budget := inputBudget(modelID)
+	chunkTokens := budget - estimateTokens(question) - 200
+	if chunkTokens <= 0 {
+		return fmt.Errorf("the question alone (~%d tokens) fills the ~%d token budget for %s", estimateTokens(question), budget, modelID)
+	}
+	chunks := splitChunks(content, chunkTokens)
+
+	base := ask.Request{
+		Model:    model,
+		APIKey:   apiKey,
+		BaseURL:  cfg.BaseURL,
+		Features: ask.FeatureFlags{Thinking: thinkFlag},
+	}
+
+	if dryRun {
+		fmt.Printf("[%s] model=%s chunks=%d budget=%d prompt=%q\n", p.Name(), modelID, len(chunks), budget, question)
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Splitting input into %d chunks for %s\n", len(chunks), modelID)
+	answers, err := mapChunks(p, base, chunks, func(chunk string, i, n int) string {
+		return mapPrompt(question, chunk, i, n)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Reduce until the partial answers fit into a single request.
+	for round := 0; estimateTokens(strings.Join(answers, "\n")) > budget && round < 3; round++ {
+		groups := splitChunks(strings.Join(answers, "\n\n"), budget)
+		fmt.Fprintf(os.Stderr, "Combining %d partial answers in %d groups\n", len(answers), len(groups))
+		answers, err = mapChunks(p, base, groups, func(group string, i, n int) string {
+			return mergePrompt(question, group, i, n)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	req := base
+	req.Messages = []ask.Message{{Role: "user", Content: reducePrompt(question, answers)}}
+	_, err = renderResponse(p.Run(context.TODO(), req), false)
+	return err
+}
+
+// mapChunks sends the prompt for every chunk with bounded concurrency.
+func mapChunks(p ask.Provider, base ask.Request, chunks []string, prompt func(chunk string, i, n int) string) ([]string, error) {
+	answers := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+	prog := newBatchProgress(len(chunks))
+	defer prog.finish()
+
+	sem := make(chan struct{}, chunkConcurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, chunk string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			req := base
+			req.Messages = []ask.Message{{Role: "user", Content: prompt(chunk, i, len(chunks))}}
+			var res ask.Result
+			res, errs[i] = ask.Collect(p.Run(context.TODO(), req), nil)
+			answers[i] = res.Text
+			prog.add(errs[i] != nil)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+	}
+	return answers, nil
+}
+
+func mapPrompt(question, chunk string, i, n int) string {
+	return fmt.Sprintf("Here is part %d of %d of a larger input:\n\n```\n%s\n```\n\n%s\n\n"+
+		"Answer using only this part. Note anything relevant for combining with the other parts. "+
+		"If this part contains nothing relevant, say so in one line.", i+1, n, chunk, question)
+}
+
+// mergePrompt condenses one group of partial answers in a reduce round
+// whose output is still too large for the final answer.
+func mergePrompt(question, group string, i, n int) string {
+	return fmt.Sprintf("A large input was split into parts and the question below was answered for each part separately. "+
+		"Here is group %d of %d of those partial answers:\n\n%s\n\nQuestion: %s\n\n"+
+		"Merge these partial answers into one, keeping every detail needed to combine it with the other groups. "+
+		"Don't answer from anything but the partial answers.", i+1, n, group, question)
+}
+
+func reducePrompt(question string, answers []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "A large input was split into %d consecutive parts and the question below was answered for each part separately.\n\n", len(answers))
+	for i, a := range answers {
+		fmt.Fprintf(&b, "### Part %d\n\n%s\n\n", i+1, strings.TrimSpace(a))
+	}
+	fmt.Fprintf(&b, "Question: %s\n\nCombine the partial answers into a single, complete answer to the question.", question)
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/laurensent/ask/pkg/config"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"日本語", 3},
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.input); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestContextLimit(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"claude-sonnet-4-5-20250929", 200000},
+		{"gpt-4o-mini", 128000},
+		{"gpt-4.1", 1000000},
+		{"gemini-2.5-flash", 1000000},
+		{"some-local-model", defaultContextLimit},
+	}
+	for _, tt := range tests {
+		if got := contextLimit(tt.model); got != tt.want {
+			t.Errorf("contextLimit(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	var paras []string
+	for i := 0; i < 50; i++ {
+		paras = append(paras, strings.Repeat("word ", 40))
+	}
+	content := strings.Join(paras, "\n\n") + "\n\n" + strings.Repeat("x", 2000)
+
+	chunks := splitChunks(content, 100)
+	if len(chunks) < 2 {
+		t.Fatalf("splitChunks() returned %d chunks, want several", len(chunks))
+	}
+	total := 0
+	for i, c := range chunks {
+		if got := estimateTokens(c); got > 100 {
+			t.Errorf("chunk %d has ~%d tokens, want <= 100", i, got)
+		}
+		total += strings.Count(c, "word")
+	}
+	if total != 50*40 {
+		t.Errorf("chunks contain %d words, want %d", total, 50*40)
+	}
+}
+
+func TestSplitRunes(t *testing.T) {
+	line := strings.Repeat("abcdefgh日本", 400000) // ~4 MB on one line
+	pieces := splitRunes(line, 1000)
+	if strings.Join(pieces, "") != line {
+		t.Fatal("pieces don't add up to the line")
+	}
+	for i, p := range pieces {
+		if n := estimateTokens(p); n > 1000 || n < 1000 && i < len(pieces)-1 {
+			t.Fatalf("piece %d is %d tokens", i, n)
+		}
+	}
+}
+
+func TestTruncateContent(t *testing.T) {
+	content := "line1\nline2\nline3\nline4"
+
+	if got := truncateContent(content, 100, "head"); got != content {
+		t.Errorf("truncateContent() changed content that fits: %q", got)
+	}
+	if got, want := truncateContent(content, 4, "head"), "line1\nline2\n[... 2 lines truncated ...]"; got != want {
+		t.Errorf("truncateContent(head) = %q, want %q", got, want)
+	}
+	if got, want := truncateContent(content, 4, "tail"), "[... 2 lines truncated ...]\nline3\nline4"; got != want {
+		t.Errorf("truncateContent(tail) = %q, want %q", got, want)
+	}
+}
+
+func TestActiveModelCLIBackend(t *testing.T) {
+	savedCfg, savedModel := cfg, model
+	defer func() { cfg, model = savedCfg, savedModel }()
+
+	tests := []struct {
+		backend, model string
+		wantPrefix     string
+	}{
+		{"", "", "claude-"},
+		{"gemini", "", "gemini-"},
+		{"codex", "", "gpt-"},
+		{"llm", "qwen3:8b", "qwen3:8b"},
+	}
+	for _, tt := range tests {
+		cfg = config.Config{Mode: "cli", CLIBackend: tt.backend}
+		model = tt.model
+		_, modelID, err := activeModel()
+		if err != nil || !strings.HasPrefix(modelID, tt.wantPrefix) {
+			t.Errorf("cli_backend %q: model %q, %v; want %s...", tt.backend, modelID, err, tt.wantPrefix)
+		}
+	}
+}
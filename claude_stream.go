@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// claudeStreamArgs are added to `claude -p` to get a line-delimited JSON
+// event stream with partial text deltas.
+var claudeStreamArgs = []string{"--output-format", "stream-json", "--verbose", "--include-partial-messages"}
+
+// claudeEvent is one line of `claude -p --output-format stream-json` output.
+// Only the fields ask uses are decoded.
+type claudeEvent struct {
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	SessionID string `json:"session_id"`
+
+	// stream_event (with --include-partial-messages)
+	Event *struct {
+		Type         string `json:"type"`
+		ContentBlock struct {
+			Type string `json:"type"`
+		} `json:"content_block"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+	} `json:"event"`
+
+	// assistant
+	Message *struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+	} `json:"message"`
+
+	// result
+	Result       string  `json:"result"`
+	IsError      bool    `json:"is_error"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	DurationMS   int64   `json:"duration_ms"`
+	NumTurns     int     `json:"num_turns"`
+}
+
+// claudeStream turns stream-json events into answer text (emit) and
+// progress notes such as tool use (note).
+type claudeStream struct {
+	emit func(text string)
+	note func(line string)
+
+	wrote     bool // some answer text has been emitted
+	newline   bool // the last emitted text ended with a newline
+	partial   bool // text arrives as stream_event deltas
+	sessionID string
+	result    *claudeEvent
+}
+
+// handle processes one line of the event stream. Lines that are not JSON
+// are passed through as text.
+func (s *claudeStream) handle(line []byte) {
+	var ev claudeEvent
+	if err := json.Unmarshal(line, &ev); err != nil || ev.Type == "" {
+		s.emit(string(line) + "\n")
+		return
+	}
+	if ev.SessionID != "" {
+		s.sessionID = ev.SessionID
+	}
+
+	switch ev.Type {
+	case "stream_event":
+		if ev.Event == nil {
+			return
+		}
+		s.partial = true
+		switch ev.Event.Type {
+		case "content_block_start":
+			if ev.Event.ContentBlock.Type == "text" && s.wrote {
+				s.emit("\n\n")
+			}
+		case "content_block_delta":
+			if ev.Event.Delta.Type == "text_delta" && ev.Event.Delta.Text != "" {
+				s.write(ev.Event.Delta.Text)
+			}
+		}
+	case "assistant":
+		if ev.Message == nil {
+			return
+		}
+		for _, c := range ev.Message.Content {
+			switch c.Type {
+			case "text":
+				// Without partial messages the full text only arrives here.
+				if s.partial || c.Text == "" {
+					continue
+				}
+				if s.wrote {
+					s.emit("\n\n")
+				}
+				s.write(c.Text)
+			case "tool_use":
+				s.note(toolUseSummary(c.Name, c.Input))
+			}
+		}
+	case "result":
+		s.result = &ev
+		// End with a newline like claude's plain text output does.
+		if s.wrote && !s.newline {
+			s.emit("\n")
+		}
+	}
+}
+
+func (s *claudeStream) write(text string) {
+	s.emit(text)
+	s.wrote = true
+	s.newline = strings.HasSuffix(text, "\n")
+}
+
+// err reports a failed run from the result event.
+func (s *claudeStream) err() error {
+	if s.result == nil || (!s.result.IsError && s.result.Subtype == "success") {
+		return nil
+	}
+	if msg := strings.TrimSpace(s.result.Result); msg != "" {
+		return fmt.Errorf("claude: %s", msg)
+	}
+	return fmt.Errorf("claude: %s", s.result.Subtype)
+}
+
+// summary describes the finished run: session ID, cost, turns and duration.
+func (s *claudeStream) summary() string {
+	if s.result == nil {
+		return ""
+	}
+	parts := []string{"session " + s.sessionID}
+	if s.result.TotalCostUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.4f", s.result.TotalCostUSD))
+	}
+	if s.result.NumTurns > 1 {
+		parts = append(parts, fmt.Sprintf("%d turns", s.result.NumTurns))
+	}
+	if s.result.DurationMS > 0 {
+		parts = append(parts, fmt.Sprintf("%.1fs", (time.Duration(s.result.DurationMS)*time.Millisecond).Seconds()))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// toolUseSummary formats a tool call as `Name(argument)`, picking the most
+// descriptive input field.
+func toolUseSummary(name string, input json.RawMessage) string {
+	var fields map[string]any
+	json.Unmarshal(input, &fields)
+	for _, key := range []string{"command", "file_path", "path", "pattern", "url", "query", "description"} {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return fmt.Sprintf("%s(%s)", name, firstLine(v, 60))
+		}
+	}
+	return name
+}
+
+// hasOutputFormat reports whether the user passed their own --output-format.
+func hasOutputFormat(args []string) bool {
+	for _, a := range args {
+		if a == "--output-format" || strings.HasPrefix(a, "--output-format=") {
+			return true
+		}
+	}
+	return false
+}
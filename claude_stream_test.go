@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestClaudeStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		wantText  string
+		wantNotes []string
+		wantErr   bool
+	}{
+		{
+			name: "partial deltas",
+			lines: []string{
+				`{"type":"system","subtype":"init","session_id":"s1"}`,
+				`{"type":"stream_event","event":{"type":"content_block_start","content_block":{"type":"text"}}}`,
+				`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"Hel"}}}`,
+				`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"lo"}}}`,
+				`{"type":"assistant","message":{"content":[{"type":"text","text":"Hello"},{"type":"tool_use","name":"Read","input":{"file_path":"main.go"}}]}}`,
+				`{"type":"stream_event","event":{"type":"content_block_start","content_block":{"type":"text"}}}`,
+				`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"Done."}}}`,
+				`{"type":"result","subtype":"success","result":"Done.","session_id":"s1"}`,
+			},
+			wantText:  "Hello\n\nDone.\n",
+			wantNotes: []string{"Read(main.go)"},
+		},
+		{
+			name: "full messages only",
+			lines: []string{
+				`{"type":"assistant","message":{"content":[{"type":"text","text":"One"}]}}`,
+				`{"type":"assistant","message":{"content":[{"type":"text","text":"Two\n"}]}}`,
+				`{"type":"result","subtype":"success"}`,
+			},
+			wantText: "One\n\nTwo\n",
+		},
+		{
+			name:     "plain text passthrough",
+			lines:    []string{`not json`},
+			wantText: "not json\n",
+		},
+		{
+			name: "error result",
+			lines: []string{
+				`{"type":"result","subtype":"error_max_turns","is_error":true}`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var text strings.Builder
+			var notes []string
+			s := &claudeStream{
+				emit: func(t string) { text.WriteString(t) },
+				note: func(l string) { notes = append(notes, l) },
+			}
+			for _, line := range tt.lines {
+				s.handle([]byte(line))
+			}
+			if got := text.String(); got != tt.wantText {
+				t.Errorf("text = %q, want %q", got, tt.wantText)
+			}
+			if strings.Join(notes, "|") != strings.Join(tt.wantNotes, "|") {
+				t.Errorf("notes = %q, want %q", notes, tt.wantNotes)
+			}
+			if (s.err() != nil) != tt.wantErr {
+				t.Errorf("err() = %v, wantErr %v", s.err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToolUseSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Bash", `{"command":"go test ./...","description":"Run tests"}`, "Bash(go test ./...)"},
+		{"Grep", `{"pattern":"TODO"}`, "Grep(TODO)"},
+		{"TodoWrite", `{"todos":[]}`, "TodoWrite"},
+		{"Task", `null`, "Task"},
+	}
+	for _, tt := range tests {
+		if got := toolUseSummary(tt.name, json.RawMessage(tt.input)); got != tt.want {
+			t.Errorf("toolUseSummary(%q, %s) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/laurensent/ask/internal/cassette"
+)
+
+// TestMain runs the ask CLI itself when a test starts this binary as a
+// helper process.
+func TestMain(m *testing.M) {
+	if os.Getenv("ASK_CLI_HELPER") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runAsk runs the CLI with config written to a fresh config directory,
+// in dir, and returns its stdout and stderr.
+func runAsk(t *testing.T, config, dir string, args ...string) (string, string, error) {
+	t.Helper()
+	return runAskEnv(t, config, dir, nil, args...)
+}
+
+// runAskEnv is runAsk with extra environment variables, which override
+// the fresh directories.
+func runAskEnv(t *testing.T, config, dir string, env []string, args ...string) (string, string, error) {
+	t.Helper()
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "config", "ask"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "config", "ask", "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"ASK_CLI_HELPER=1",
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, "config"),
+		"XDG_DATA_HOME="+filepath.Join(home, "data"),
+		"XDG_CACHE_HOME="+filepath.Join(home, "cache"),
+		"ANTHROPIC_API_KEY=",
+		"ASK_MOCK_SCRIPT=",
+	)
+	cmd.Env = append(cmd.Env, env...)
+	var stdout, stderr strings.Builder
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+func mockConfig(t *testing.T) string {
+	script, err := filepath.Abs(filepath.Join("testdata", "mock_script.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return `{"mode": "api", "provider": "mock", "agent": {"auto_approve": true}, "mock_script": "` + script + `"}`
+}
+
+func TestCLIMock(t *testing.T) {
+	out, stderr, err := runAsk(t, mockConfig(t), ".", "what", "is", "the", "capital", "of", "France")
+	if err != nil {
+		t.Fatalf("%v: %s", err, stderr)
+	}
+	for _, want := range []string{"Paris is the capital of France. [1]", "**Sources**", "1. Paris - Wikipedia <https://en.wikipedia.org/wiki/Paris>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+
+	_, stderr, err = runAsk(t, mockConfig(t), ".", "rate limit me")
+	if err == nil || !strings.Contains(stderr, "Error: 429 Too Many Requests") {
+		t.Errorf("error run: %v, stderr %q", err, stderr)
+	}
+}
+
+func TestCLIAgent(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, stderr, err := runAsk(t, mockConfig(t), dir, "--agent", "what do my notes say")
+	if err != nil {
+		t.Fatalf("%v: %s", err, stderr)
+	}
+	if !strings.Contains(stderr, "read_file") || strings.TrimSpace(out) != "The notes say hello." {
+		t.Errorf("stdout %q, stderr %q", out, stderr)
+	}
+}
+
+// TestCLIReplay runs the CLI against the same exchange as the anthropic
+// thinking case of TestReplay in pkg/ask, sharing its cassette.
+func TestCLIReplay(t *testing.T) {
+	url := cassette.Server(t, filepath.Join("pkg", "ask", "testdata", "cassettes", "anthropic_thinking.json"), "https://api.anthropic.com")
+	key := "test-key"
+	if cassette.Recording() {
+		key = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	config := `{"mode": "api", "provider": "anthropic", "api_key": "` + key + `", "base_url": "` + url + `", "thinking": true}`
+	out, stderr, err := runAsk(t, config, ".", "-m", "sonnet", "What is the capital of Australia?")
+	if err != nil {
+		t.Fatalf("%v: %s", err, stderr)
+	}
+	if !cassette.Recording() && strings.TrimSpace(out) != "The capital of Australia is **Canberra**." {
+		t.Errorf("output = %q", out)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
@@ -9,6 +10,16 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+
+	"github.com/laurensent/ask/pkg/history"
+)
+
+// Piped input leads the prompt so that follow-up questions about the same
+// input share a prefix the provider can cache.
+const (
+	pipeHeader        = "Here is the input data:\n\n```\n"
+	pipeHeaderNoQuery = "Here is some data. Please analyze it:\n\n```\n"
+	pipeFooter        = "\n```\n\n"
 )
 
 // buildPrompt assembles the final prompt from pipe input and user arguments.
@@ -16,14 +27,28 @@ func buildPrompt(args []string, pipeContent string) string {
 	prompt := strings.Join(args, " ")
 
 	if pipeContent != "" && prompt != "" {
-		return fmt.Sprintf("Here is the input data:\n\n```\n%s\n```\n\n%s", pipeContent, prompt)
+		return pipeHeader + pipeContent + pipeFooter + prompt
 	}
 	if pipeContent != "" {
-		return fmt.Sprintf("Here is some data. Please analyze it:\n\n```\n%s\n```", pipeContent)
+		return fmt.Sprintf("%s%s\n```", pipeHeaderNoQuery, pipeContent)
 	}
 	return prompt
 }
 
+// pipePrefixLen returns the length of the piped input that starts a
+// prompt from buildPrompt, or 0 if it has none.
+func pipePrefixLen(prompt string) int {
+	if strings.HasPrefix(prompt, pipeHeaderNoQuery) {
+		return len(prompt)
+	}
+	if strings.HasPrefix(prompt, pipeHeader) {
+		if i := strings.LastIndex(prompt, pipeFooter); i >= 0 {
+			return i + len(pipeFooter)
+		}
+	}
+	return 0
+}
+
 // onFirstWriteWriter wraps an io.Writer and calls a callback on the first Write.
 type onFirstWriteWriter struct {
 	w    io.Writer
@@ -36,37 +61,69 @@ func (o *onFirstWriteWriter) Write(p []byte) (int, error) {
 	return o.w.Write(p)
 }
 
-// runClaude executes the claude CLI in single-shot mode with the given prompt.
-func runClaude(prompt, model string) error {
-	claudePath, err := findClaude()
+// resumeSession returns the session to continue for -c or --resume, or ""
+// to start a new one.
+func resumeSession() (string, error) {
+	if !continueFlag && resumeID == "" {
+		return "", nil
+	}
+	if cfg.Mode == "api" {
+		return "", fmt.Errorf("-c and --resume need CLI mode (use `ask chat --resume` for API sessions)")
+	}
+	name, b, err := selectBackend(cfg)
 	if err != nil {
-		return fmt.Errorf("claude CLI not found in PATH: %w\nInstall it from: https://docs.anthropic.com/en/docs/claude-code", err)
+		return "", err
 	}
+	if b.ResumeFlag == "" {
+		return "", fmt.Errorf("the %s backend doesn't support resuming sessions", name)
+	}
+	if continueFlag {
+		return history.LastSessionID()
+	}
+	return history.ExpandSessionID(resumeID), nil
+}
 
-	args := []string{"-p", prompt}
+// runCLIWithHistory runs prompt through the CLI backend and records it in
+// history together with the session ID it reports, so it can be resumed later.
+func runCLIWithHistory(prompt, model, resume string) error {
+	sessionID, err := runCLI(prompt, model, resume)
+	history.Add(prompt, sessionID)
+	return err
+}
 
-	if model != "" {
-		args = append(args, "--model", model)
+// runCLI executes the configured CLI backend in single-shot mode with the
+// given prompt, continuing session resume if set. Backends with stream-json
+// output are rendered like API mode; others are buffered and rendered once
+// they exit. It returns the session ID the backend reports, if any.
+func runCLI(prompt, model, resume string) (string, error) {
+	name, b, err := selectBackend(cfg)
+	if err != nil {
+		return "", err
 	}
 
-	// Append passthrough flags for claude
-	args = append(args, passthrough...)
+	args := b.commandArgs(prompt, model, resume, passthrough)
 
 	if dryRun {
-		fmt.Printf("claude -p %q", prompt)
-		if model != "" {
-			fmt.Printf(" --model %s", model)
-		}
-		for _, a := range passthrough {
-			fmt.Printf(" %s", a)
+		fmt.Println(formatCommand(b.Binary, args))
+		return "", nil
+	}
+
+	path, err := exec.LookPath(b.Binary)
+	if err != nil {
+		msg := fmt.Sprintf("%s CLI not found in PATH: %v", b.Binary, err)
+		if b.InstallURL != "" {
+			msg += "\nInstall it from: " + b.InstallURL
 		}
-		fmt.Println()
-		return nil
+		return "", errors.New(msg)
 	}
 
-	cmd := exec.Command(claudePath, args...)
+	cmd := exec.Command(path, args...)
 	cmd.Stdin = os.Stdin
 
+	if b.streams(passthrough) {
+		return runCLIStream(name, cmd)
+	}
+
 	needRender := !rawOutput && isStdoutTerminal()
 
 	var outBuf bytes.Buffer
@@ -81,27 +138,77 @@ func runClaude(prompt, model string) error {
 	}
 	cmd.Stderr = &onFirstWriteWriter{w: os.Stderr, fn: sp.Stop}
 
-	if err := cmd.Run(); err != nil {
+	if err := cliRunError(name, cmd.Run()); err != nil {
 		sp.Stop()
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			return fmt.Errorf("claude exited with code %d", exitErr.ExitCode())
-		}
-		return fmt.Errorf("failed to run claude: %w", err)
+		return "", err
 	}
 	sp.Stop()
 
 	if needRender {
 		raw := outBuf.String()
 		if raw == "" {
-			return nil
+			return "", nil
 		}
 		rendered, err := renderMarkdown(strings.TrimSpace(raw))
 		if err != nil {
 			fmt.Print(raw)
-			return nil
+			return "", nil
 		}
 		fmt.Print(rendered)
 	}
-	return nil
+	return "", nil
+}
+
+// runCLIStream runs cmd with stream-json output, feeding text deltas into
+// runStreaming and reporting tool use, cost and session ID on stderr.
+func runCLIStream(name string, cmd *exec.Cmd) (string, error) {
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w", name, err)
+	}
+
+	stream := &claudeStream{note: func(line string) {
+		fmt.Fprintf(os.Stderr, "⏺ %s\n", line)
+	}}
+	err = runStreaming(func(emit func(string)) error {
+		stream.emit = emit
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("failed to run %s: %w", name, err)
+		}
+		r := bufio.NewReader(stdout)
+		for {
+			line, readErr := r.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				stream.handle(line)
+			}
+			if readErr != nil {
+				break
+			}
+		}
+		waitErr := cmd.Wait()
+		if err := stream.err(); err != nil {
+			return err
+		}
+		return cliRunError(name, waitErr)
+	})
+	if err != nil {
+		return stream.sessionID, err
+	}
+	if summary := stream.summary(); summary != "" {
+		fmt.Fprintln(os.Stderr, wizardDim.Render(summary))
+	}
+	return stream.sessionID, nil
+}
+
+// cliRunError converts the error from running a backend into a user-facing one.
+func cliRunError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s exited with code %d", name, exitErr.ExitCode())
+	}
+	return fmt.Errorf("failed to run %s: %w", name, err)
 }
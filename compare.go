@@ -0,0 +1,446 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+// compareTarget is one provider/model pair in a --compare run.
+type compareTarget struct {
+	Provider string
+	Model    string
+}
+
+func (t compareTarget) String() string { return t.Provider + ":" + t.Model }
+
+// parseTarget resolves a model reference to a provider/model pair. The item
+// is "provider:model" or a bare alias, which is looked up across all
+// registered providers; unknown names are treated as model IDs for
+// defaultProvider.
+func parseTarget(item, defaultProvider string) (compareTarget, error) {
+	if name, m, ok := strings.Cut(item, ":"); ok {
+		if _, known := ask.Lookup(name); known {
+			if m == "" {
+				return compareTarget{}, fmt.Errorf("missing model in %q", item)
+			}
+			return compareTarget{Provider: name, Model: m}, nil
+		}
+	}
+	if name, ok := providerForAlias(item); ok {
+		return compareTarget{Provider: name, Model: item}, nil
+	}
+	return compareTarget{Provider: defaultProvider, Model: item}, nil
+}
+
+// parseCompareSpec splits a comma-separated --compare list into targets.
+func parseCompareSpec(spec, defaultProvider string) ([]compareTarget, error) {
+	var targets []compareTarget
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		t, err := parseTarget(item, defaultProvider)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	if len(targets) < 2 {
+		return nil, fmt.Errorf("--compare needs at least two models (e.g. sonnet,gpt4o,flash)")
+	}
+	return targets, nil
+}
+
+// providerForAlias returns the first provider (by name) that defines alias.
+func providerForAlias(alias string) (string, bool) {
+	for _, name := range ask.Names() {
+		p, _ := ask.Lookup(name)
+		for _, a := range p.ModelAliases() {
+			if a == alias {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// compareRun tracks the state of one target while the comparison runs.
+type compareRun struct {
+	target   compareTarget
+	provider ask.Provider
+	req      ask.Request
+
+	text     string
+	rendered string
+	first    time.Duration
+	elapsed  time.Duration
+	usage    ask.Usage
+	err      error
+	done     bool
+}
+
+// compareEvent is a streamed chunk or completion from one run.
+type compareEvent struct {
+	idx  int
+	text string
+	at   time.Duration
+	done bool
+	res  ask.Result
+	err  error
+}
+
+func (r *compareRun) apply(ev compareEvent) {
+	if ev.done {
+		r.done = true
+		r.elapsed = ev.at
+		r.usage = ev.res.Usage
+		r.err = ev.err
+		return
+	}
+	if r.first == 0 {
+		r.first = ev.at
+	}
+	r.text += ev.text
+}
+
+func (r *compareRun) stats() string {
+	if r.err != nil {
+		return "error: " + r.err.Error()
+	}
+	if !r.done {
+		return "running..."
+	}
+	return fmt.Sprintf("first token %.2fs · total %.2fs · %d in / %d out tokens",
+		r.first.Seconds(), r.elapsed.Seconds(), r.usage.InputTokens, r.usage.OutputTokens)
+}
+
+// markdown formats a run as a section with its answer and stats. Runs that
+// never finished are marked canceled.
+func (r *compareRun) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", r.target)
+	if r.text != "" {
+		fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(r.text))
+	}
+	stats := r.stats()
+	if !r.done && r.err == nil {
+		stats = "canceled"
+	}
+	fmt.Fprintf(&b, "> %s · %s\n\n", r.provider.ResolveModel(r.target.Model), stats)
+	return b.String()
+}
+
+// newCompareRuns resolves providers and credentials for each target.
+func newCompareRuns(targets []compareTarget, prompt string) ([]*compareRun, error) {
+	runs := make([]*compareRun, 0, len(targets))
+	for _, t := range targets {
+		p, err := ask.Get(t.Provider)
+		if err != nil {
+			return nil, err
+		}
+		pcfg := cfg.ForProvider(p.Name())
+		apiKey, err := pcfg.RequireAPIKey(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		req := ask.UserRequest(prompt)
+		req.Model = t.Model
+		req.APIKey = apiKey
+		req.BaseURL = pcfg.BaseURL
+		req.Features = ask.FeatureFlags{Thinking: thinkFlag, WebSearch: searchFlag}
+		runs = append(runs, &compareRun{target: t, provider: p, req: req})
+	}
+	return runs, nil
+}
+
+// startCompare runs all targets concurrently. The returned channel is closed
+// once every run has sent its completion event, or ctx is canceled.
+func startCompare(ctx context.Context, runs []*compareRun) <-chan compareEvent {
+	ch := make(chan compareEvent, 64)
+	send := func(ev compareEvent) {
+		select {
+		case ch <- ev:
+		case <-ctx.Done():
+		}
+	}
+	var wg sync.WaitGroup
+	for i, r := range runs {
+		wg.Add(1)
+		go func(i int, p ask.Provider, req ask.Request) {
+			defer wg.Done()
+			start := time.Now()
+			res, err := ask.Collect(p.Run(ctx, req), func(text string) {
+				send(compareEvent{idx: i, text: text, at: time.Since(start)})
+			})
+			send(compareEvent{idx: i, done: true, at: time.Since(start), res: res, err: err})
+		}(i, r.provider, r.req)
+	}
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+	return ch
+}
+
+// runCompare sends prompt to every model in spec and shows the answers side by side.
+func runCompare(prompt, spec string) error {
+	targets, err := parseCompareSpec(spec, cfg.ResolvedProvider())
+	if err != nil {
+		return err
+	}
+	runs, err := newCompareRuns(targets, prompt)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		for _, r := range runs {
+			fmt.Printf("[%s] model=%s thinking=%v search=%v prompt=%q\n", r.target.Provider, r.provider.ResolveModel(r.target.Model), r.req.Features.Thinking, r.req.Features.WebSearch, prompt)
+		}
+		return nil
+	}
+
+	if rawOutput || !isStdoutTerminal() {
+		return runComparePlain(runs)
+	}
+	return runCompareTUI(runs)
+}
+
+// runComparePlain prints one markdown section per target, in target order.
+func runComparePlain(runs []*compareRun) error {
+	next := 0
+	for ev := range startCompare(context.Background(), runs) {
+		runs[ev.idx].apply(ev)
+		for next < len(runs) && runs[next].done {
+			fmt.Print(runs[next].markdown())
+			next++
+		}
+	}
+	return compareError(runs)
+}
+
+// compareError names the targets whose runs failed, or returns nil.
+// Runs the user canceled by quitting don't count.
+func compareError(runs []*compareRun) error {
+	var failed []string
+	for _, r := range runs {
+		if r.err != nil && !errors.Is(r.err, context.Canceled) {
+			failed = append(failed, r.target.String())
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d targets failed: %s", len(failed), len(runs), strings.Join(failed, ", "))
+}
+
+// --- bubbletea comparison view ---
+
+const compareMinColumn = 40
+
+var (
+	compareBorder = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
+	compareFocus  = compareBorder.BorderForeground(lipgloss.Color("#E36C38"))
+)
+
+type compareEventMsg compareEvent
+type compareClosedMsg struct{}
+
+type compareModel struct {
+	runs   []*compareRun
+	panes  []viewport.Model
+	events <-chan compareEvent
+	cancel context.CancelFunc
+	focus  int
+	width  int
+	height int
+}
+
+func waitForCompare(ch <-chan compareEvent) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-ch
+		if !ok {
+			return compareClosedMsg{}
+		}
+		return compareEventMsg(ev)
+	}
+}
+
+func (m compareModel) Init() tea.Cmd {
+	return waitForCompare(m.events)
+}
+
+// columns reports whether panes fit side by side; otherwise a tabbed view is used.
+func (m compareModel) columns() bool {
+	return m.width/len(m.runs) >= compareMinColumn
+}
+
+func (m compareModel) paneWidth() int {
+	if m.columns() {
+		return m.width/len(m.runs) - 2
+	}
+	return m.width - 2
+}
+
+func (m *compareModel) layout() {
+	w := m.paneWidth()
+	h := max(m.height-6, 1)
+	if !m.columns() {
+		h = max(m.height-7, 1)
+	}
+	for i := range m.panes {
+		m.panes[i].Width = w
+		m.panes[i].Height = h
+		m.runs[i].rendered = ""
+		m.updatePane(i)
+	}
+}
+
+func (m *compareModel) updatePane(i int) {
+	r := m.runs[i]
+	w := m.paneWidth()
+	content := lipgloss.NewStyle().Width(w).Render(r.text)
+	if r.done && r.err == nil && r.text != "" {
+		if r.rendered == "" {
+			if out, err := renderMarkdownWidth(strings.TrimSpace(r.text), w); err == nil {
+				r.rendered = out
+			} else {
+				r.rendered = content
+			}
+		}
+		content = r.rendered
+	}
+	atBottom := m.panes[i].AtBottom()
+	m.panes[i].SetContent(content)
+	if !r.done && atBottom {
+		m.panes[i].GotoBottom()
+	}
+}
+
+func (m compareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		return m, nil
+	case compareEventMsg:
+		m.runs[msg.idx].apply(compareEvent(msg))
+		if m.width > 0 {
+			m.updatePane(msg.idx)
+		}
+		return m, waitForCompare(m.events)
+	case compareClosedMsg:
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			m.cancel()
+			return m, tea.Quit
+		case "tab", "right", "l":
+			m.focus = (m.focus + 1) % len(m.runs)
+		case "shift+tab", "left", "h":
+			m.focus = (m.focus + len(m.runs) - 1) % len(m.runs)
+		default:
+			var cmd tea.Cmd
+			m.panes[m.focus], cmd = m.panes[m.focus].Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func (m compareModel) renderPane(i int) string {
+	r := m.runs[i]
+	style := compareBorder
+	if i == m.focus {
+		style = compareFocus
+	}
+	header := wizardLabel.Render(r.target.String())
+	footer := wizardDim.Render(firstLine(r.stats(), m.paneWidth()))
+	if r.err != nil {
+		footer = chatErrorStyle.Render(firstLine(r.stats(), m.paneWidth()))
+	}
+	return style.Width(m.paneWidth()).Render(header + "\n" + m.panes[i].View() + "\n" + footer)
+}
+
+func (m compareModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+	help := wizardDim.Render("Tab/←→ = switch  ↑↓/PgUp/PgDn = scroll  q = quit")
+
+	if m.columns() {
+		cols := make([]string, len(m.runs))
+		for i := range m.runs {
+			cols[i] = m.renderPane(i)
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, cols...) + "\n" + help
+	}
+
+	var tabs []string
+	for i, r := range m.runs {
+		label := " " + r.target.String() + " "
+		if i == m.focus {
+			tabs = append(tabs, wizardSelected.Render("["+label+"]"))
+		} else {
+			tabs = append(tabs, wizardDim.Render(" "+label+" "))
+		}
+	}
+	return strings.Join(tabs, "") + "\n" + m.renderPane(m.focus) + "\n" + help
+}
+
+// runCompareTUI shows the runs in a full-screen view, then prints the answers
+// and a summary table.
+func runCompareTUI(runs []*compareRun) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := compareModel{
+		runs:   runs,
+		panes:  make([]viewport.Model, len(runs)),
+		events: startCompare(ctx, runs),
+		cancel: cancel,
+	}
+	for i := range m.panes {
+		m.panes[i] = viewport.New(0, 0)
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+	// The alt screen is gone once the view quits; keep the answers.
+	for _, r := range runs {
+		fmt.Print(r.markdown())
+	}
+
+	w := tabwriter.NewWriter(os.Stderr, 2, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "TARGET\tMODEL ID\tFIRST TOKEN\tTOTAL\tIN\tOUT\tSTATUS\n")
+	for _, r := range runs {
+		status := "ok"
+		switch {
+		case r.err != nil:
+			status = r.err.Error()
+		case !r.done:
+			status = "canceled"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2fs\t%.2fs\t%d\t%d\t%s\n", r.target, r.provider.ResolveModel(r.target.Model),
+			r.first.Seconds(), r.elapsed.Seconds(), r.usage.InputTokens, r.usage.OutputTokens, firstLine(status, 60))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return compareError(runs)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCompareSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []compareTarget
+		wantErr bool
+	}{
+		{
+			"aliases across providers",
+			"sonnet,gpt4o,flash",
+			[]compareTarget{{"anthropic", "sonnet"}, {"openai", "gpt4o"}, {"gemini", "flash"}},
+			false,
+		},
+		{
+			"explicit provider pairs",
+			"openai:o3-mini, ollama:llama3:8b",
+			[]compareTarget{{"openai", "o3-mini"}, {"ollama", "llama3:8b"}},
+			false,
+		},
+		{
+			"unknown name uses default provider",
+			"claude-opus-4-1,haiku",
+			[]compareTarget{{"anthropic", "claude-opus-4-1"}, {"anthropic", "haiku"}},
+			false,
+		},
+		{"single model", "sonnet", nil, true},
+		{"missing model", "openai:,sonnet", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCompareSpec(tt.spec, "anthropic")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCompareSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCompareSpec(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLICompareFailure(t *testing.T) {
+	if _, stderr, err := runAsk(t, mockConfig(t), ".", "--compare", "mock,mock:mock", "capital of France"); err != nil {
+		t.Errorf("compare: %v: %s", err, stderr)
+	}
+	_, stderr, err := runAsk(t, mockConfig(t), ".", "--compare", "mock,mock:mock", "rate limit me")
+	if err == nil || !strings.Contains(stderr, "2 of 2 targets failed: mock:mock, mock:mock") {
+		t.Errorf("failed compare: err %v, stderr %q", err, stderr)
+	}
+}
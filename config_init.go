@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -9,6 +8,8 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
 )
 
 type configStep int
@@ -41,7 +42,7 @@ type configWizard struct {
 	step     configStep
 	cursor   int
 	input    textinput.Model
-	config   appConfig
+	config   config.Config
 	width    int
 	height   int
 	done     bool
@@ -55,14 +56,16 @@ func newConfigWizard() configWizard {
 	ti.EchoCharacter = '*'
 	ti.Focus()
 
+	// Start from the saved config so settings the wizard doesn't ask about
+	// (context_limit, cli_backends, providers) survive a re-run.
+	c := config.Load()
+	c.Mode, c.Provider, c.APIKey, c.BaseURL, c.DefaultModel = "cli", "", "", "", ""
+	c.RawOutput, c.Theme, c.Thinking, c.WebSearch = false, "auto", true, false
+
 	return configWizard{
-		step:  stepMode,
-		input: ti,
-		config: appConfig{
-			Mode:     "cli",
-			Theme:    "auto",
-			Thinking: true,
-		},
+		step:   stepMode,
+		input:  ti,
+		config: c,
 	}
 }
 
@@ -77,13 +80,13 @@ func (m configWizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 	case tea.KeyMsg:
-		if m.step == stepAPIKey || m.step == stepBaseURL {
+		if m.textInputStep() {
 			return m.updateTextInput(msg)
 		}
 		return m.updateSelection(msg)
 	}
 
-	if m.step == stepAPIKey || m.step == stepBaseURL {
+	if m.textInputStep() {
 		var cmd tea.Cmd
 		m.input, cmd = m.input.Update(msg)
 		return m, cmd
@@ -103,6 +106,13 @@ func (m configWizard) updateTextInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.config.BaseURL = m.input.Value()
 			m.step = stepModel
 			m.cursor = 0
+			if m.isAzure() {
+				m.prepareDeploymentInput()
+			}
+		} else if m.step == stepModel {
+			m.config.DefaultModel = strings.TrimSpace(m.input.Value())
+			m.step = stepRawOutput
+			m.cursor = 0
 		}
 		return m, nil
 	case tea.KeyEsc, tea.KeyCtrlC:
@@ -114,9 +124,28 @@ func (m configWizard) updateTextInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// textInputStep reports whether the current step takes free text rather
+// than a selection. Azure deployments are user-named, so its model step
+// is typed in.
+func (m configWizard) textInputStep() bool {
+	return m.step == stepAPIKey || m.step == stepBaseURL || (m.step == stepModel && m.isAzure())
+}
+
+func (m configWizard) isAzure() bool {
+	return m.config.Mode == "api" && m.config.ResolvedProvider() == "azure"
+}
+
+// prepareDeploymentInput sets up the text input for the Azure deployment name.
+func (m *configWizard) prepareDeploymentInput() {
+	m.input.Placeholder = "my-gpt-4o"
+	m.input.EchoMode = textinput.EchoNormal
+	m.input.EchoCharacter = 0
+	m.input.SetValue(os.Getenv("AZURE_OPENAI_DEPLOYMENT"))
+}
+
 // nextAfterAPIKey determines the next step after API key entry.
 func (m configWizard) nextAfterAPIKey() configStep {
-	prov := m.config.resolvedProvider()
+	prov := m.config.ResolvedProvider()
 	// Anthropic and Gemini have fixed base URLs, skip base URL step
 	if prov == "anthropic" || prov == "gemini" {
 		return stepModel
@@ -131,11 +160,14 @@ func (m *configWizard) prepareBaseURLInput() {
 	m.input.SetValue("")
 
 	// Pre-fill with provider default URL
-	if p, err := getProvider(m.config.resolvedProvider()); err == nil {
-		if ocp, ok := p.(openaiCompatProvider); ok {
-			m.input.SetValue(ocp.defaultURL)
+	if p, err := ask.Get(m.config.ResolvedProvider()); err == nil {
+		if b, ok := p.(ask.BaseURLProvider); ok {
+			m.input.SetValue(b.DefaultBaseURL())
 		}
 	}
+	if m.isAzure() {
+		m.input.Placeholder = "https://<resource>.openai.azure.com"
+	}
 }
 
 func (m configWizard) updateSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -234,7 +266,7 @@ func (m configWizard) confirmSelection() (tea.Model, tea.Cmd) {
 }
 
 func (m configWizard) apiKeyPlaceholder() string {
-	switch m.config.resolvedProvider() {
+	switch m.config.ResolvedProvider() {
 	case "anthropic":
 		return "sk-ant-..."
 	case "openai":
@@ -243,6 +275,12 @@ func (m configWizard) apiKeyPlaceholder() string {
 		return "AI..."
 	case "xai":
 		return "xai-..."
+	case "deepseek":
+		return "sk-..."
+	case "groq":
+		return "gsk_..."
+	case "openrouter":
+		return "sk-or-..."
 	default:
 		return ""
 	}
@@ -253,7 +291,7 @@ func (m configWizard) currentOptions() []string {
 	case stepMode:
 		return []string{"cli", "api"}
 	case stepProvider:
-		return providerNames()
+		return ask.Names()
 	case stepModel:
 		return m.modelOptions()
 	case stepRawOutput:
@@ -273,7 +311,7 @@ func (m configWizard) currentOptions() []string {
 
 func (m configWizard) modelOptions() []string {
 	if m.config.Mode == "api" {
-		if p, err := getProvider(m.config.resolvedProvider()); err == nil {
+		if p, err := ask.Get(m.config.ResolvedProvider()); err == nil {
 			return p.ModelAliases()
 		}
 	}
@@ -290,8 +328,14 @@ func (m configWizard) stepTitle() string {
 	case stepAPIKey:
 		return "API Key"
 	case stepBaseURL:
+		if m.isAzure() {
+			return "Endpoint"
+		}
 		return "Base URL"
 	case stepModel:
+		if m.isAzure() {
+			return "Deployment"
+		}
 		return "Default Model"
 	case stepRawOutput:
 		return "Raw Output"
@@ -311,14 +355,23 @@ func (m configWizard) stepTitle() string {
 func (m configWizard) stepDescription() string {
 	switch m.step {
 	case stepMode:
-		return "cli = use claude CLI, api = call LLM provider API directly"
+		return "cli = use an agentic CLI (claude by default), api = call LLM provider API directly"
 	case stepProvider:
 		return "Choose the LLM provider for API mode"
 	case stepAPIKey:
-		return fmt.Sprintf("Enter your %s API key", m.config.resolvedProvider())
+		if m.isAzure() {
+			return "Enter your Azure OpenAI API key, or leave empty to sign in with Entra ID (az login)"
+		}
+		return fmt.Sprintf("Enter your %s API key", m.config.ResolvedProvider())
 	case stepBaseURL:
+		if m.isAzure() {
+			return "Azure OpenAI resource endpoint"
+		}
 		return "Custom API base URL (edit or press Enter to accept default)"
 	case stepModel:
+		if m.isAzure() {
+			return "Name of the deployment to use by default"
+		}
 		return "Choose the default model for queries"
 	case stepRawOutput:
 		return "Skip markdown rendering and output raw text?"
@@ -351,7 +404,7 @@ func (m configWizard) View() string {
 	b.WriteString(wizardLabel.Render(m.stepTitle()) + "\n")
 	b.WriteString(wizardDim.Render(m.stepDescription()) + "\n\n")
 
-	if m.step == stepAPIKey || m.step == stepBaseURL {
+	if m.textInputStep() {
 		b.WriteString(m.input.View() + "\n\n")
 		b.WriteString(wizardDim.Render("Enter = confirm  Esc = cancel"))
 		return b.String()
@@ -387,7 +440,7 @@ func (m configWizard) renderSummary() string {
 
 	kv("Mode", m.config.Mode)
 	if m.config.Mode == "api" {
-		kv("Provider", m.config.resolvedProvider())
+		kv("Provider", m.config.ResolvedProvider())
 		masked := m.config.APIKey
 		if len(masked) > 8 {
 			masked = masked[:4] + strings.Repeat("*", len(masked)-8) + masked[len(masked)-4:]
@@ -411,21 +464,6 @@ func (m configWizard) renderSummary() string {
 	return b.String()
 }
 
-func saveConfig(cfg appConfig) error {
-	if err := os.MkdirAll(configDir(), 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-	data = append(data, '\n')
-	if err := os.WriteFile(configPath(), data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
-	}
-	return nil
-}
-
 func runConfigInit() error {
 	m := newConfigWizard()
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
@@ -443,9 +481,9 @@ func runConfigInit() error {
 		return nil
 	}
 
-	if err := saveConfig(final.config); err != nil {
+	if err := config.Save(final.config); err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stderr, "Configuration saved to %s\n", configPath())
+	fmt.Fprintf(os.Stderr, "Configuration saved to %s\n", config.Path())
 	return nil
 }
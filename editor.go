@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorScissors marks the start of the ignored section in an editor buffer.
+const editorScissors = "# ------------------------ >8 ------------------------"
+
+// editorDoneMsg is sent back to a TUI when the external editor exits.
+type editorDoneMsg struct {
+	text string
+	err  error
+}
+
+// editorArgs returns the user's editor command from $VISUAL or $EDITOR, defaulting to vi.
+func editorArgs() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if args := strings.Fields(os.Getenv(env)); len(args) > 0 {
+			return args
+		}
+	}
+	return []string{"vi"}
+}
+
+// writeEditorFile writes initial to a temp file for editing and returns its path.
+func writeEditorFile(initial string) (string, error) {
+	f, err := os.CreateTemp("", "ask-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(initial); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// readEditorFile reads back an edited temp file, removes it, and strips the ignored section.
+func readEditorFile(path string) (string, error) {
+	defer os.Remove(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read temp file: %w", err)
+	}
+	return stripScissors(string(data)), nil
+}
+
+// stripScissors drops everything from the scissors line onward and trims whitespace.
+func stripScissors(s string) string {
+	if i := strings.Index(s, editorScissors); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// editText opens initial in the user's editor and returns the edited text.
+// The editor is attached to the controlling terminal so it works even when
+// stdin is a pipe.
+func editText(initial string) (string, error) {
+	path, err := writeEditorFile(initial)
+	if err != nil {
+		return "", err
+	}
+
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("editor requires a terminal: %w", err)
+	}
+	defer tty.Close()
+
+	args := editorArgs()
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = tty
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+	return readEditorFile(path)
+}
+
+// editTextCmd suspends the running TUI, opens text in the editor, and
+// reports the result as an editorDoneMsg.
+func editTextCmd(text string) tea.Cmd {
+	path, err := writeEditorFile(text)
+	if err != nil {
+		return func() tea.Msg { return editorDoneMsg{err: err} }
+	}
+	args := editorArgs()
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			os.Remove(path)
+			return editorDoneMsg{err: fmt.Errorf("editor %s failed: %w", args[0], err)}
+		}
+		text, err := readEditorFile(path)
+		return editorDoneMsg{text: text, err: err}
+	})
+}
+
+// editorTemplate builds the initial editor buffer for `ask -e`: the prompt
+// so far, followed by an ignored summary of the piped input.
+func editorTemplate(prompt, pipeContent string) string {
+	var b strings.Builder
+	if prompt != "" {
+		b.WriteString(prompt + "\n")
+	}
+	b.WriteString("\n" + editorScissors + "\n")
+	b.WriteString("# Write your prompt above this line. Everything below it is ignored.\n")
+	if pipeContent != "" {
+		lines := strings.Split(strings.TrimRight(pipeContent, "\n"), "\n")
+		fmt.Fprintf(&b, "#\n# Piped input (%d lines, %d bytes) will be attached:\n#\n", len(lines), len(pipeContent))
+		for i, line := range lines {
+			if i == 10 {
+				fmt.Fprintf(&b, "#   ... %d more lines\n", len(lines)-10)
+				break
+			}
+			b.WriteString("#   " + firstLine(line, 100) + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestStripScissors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no scissors", "  explain this\n", "explain this"},
+		{"drops section", "explain this\n\n" + editorScissors + "\n# ignored\n", "explain this"},
+		{"keeps markdown headings", "# Title\nbody\n" + editorScissors + "\n", "# Title\nbody"},
+		{"empty prompt", "\n" + editorScissors + "\n# ignored", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripScissors(tt.input); got != tt.want {
+				t.Errorf("stripScissors(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorTemplate(t *testing.T) {
+	got := stripScissors(editorTemplate("review this", "line1\nline2\n"))
+	if got != "review this" {
+		t.Errorf("editorTemplate round trip = %q, want %q", got, "review this")
+	}
+}
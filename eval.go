@@ -0,0 +1,489 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var evalReportPath string
+var evalJudge string
+var evalConcurrency int
+
+var evalCmd = &cobra.Command{
+	Use:   "eval SUITE",
+	Short: "Score prompts against models with assertions",
+	Long: `Run every case in the YAML file SUITE against each target and check the
+answers with its assertions:
+
+  targets: [anthropic:sonnet, openai:gpt4o]
+  judge: anthropic:haiku          # grades rubric assertions
+  system: Answer in one sentence.
+  cases:
+    - name: capital
+      prompt: What is the capital of France?
+      assert:
+        - contains: Paris
+        - regex: '(?i)\bparis\b'
+        - rubric: Names Paris and nothing else.
+    - name: person
+      prompt: Return Ada Lovelace as JSON with name and born.
+      assert:
+        - json_schema: {type: object, required: [name, born]}
+
+Assertions are contains, regex, equals (ignoring surrounding whitespace),
+json_schema (inline, or a path relative to SUITE) and rubric. The command
+prints a pass/fail table and exits non-zero if any check fails.`,
+	Example: `  ask eval suite.yaml
+  ask eval suite.yaml --report report.json
+  ask eval suite.yaml --judge openai:gpt4o -j 8`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runEval(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	evalCmd.Flags().StringVar(&evalReportPath, "report", "", "write a JSON report to this file")
+	evalCmd.Flags().StringVar(&evalJudge, "judge", "", "provider:model that grades rubric assertions (overrides the suite)")
+	evalCmd.Flags().IntVarP(&evalConcurrency, "concurrency", "j", 4, "number of prompts to run at once")
+}
+
+// evalSuite is the YAML file read by ask eval.
+type evalSuite struct {
+	Targets []string   `yaml:"targets"` // provider:model or aliases; default: the configured model
+	Judge   string     `yaml:"judge"`
+	System  string     `yaml:"system"`
+	Cases   []evalCase `yaml:"cases"`
+}
+
+// evalCase is one prompt and the assertions its answer must pass.
+type evalCase struct {
+	Name   string          `yaml:"name"`
+	Prompt string          `yaml:"prompt"`
+	System string          `yaml:"system"` // replaces the suite's system prompt
+	Assert []evalAssertion `yaml:"assert"`
+}
+
+// evalAssertion is one check of an answer. Exactly one field is set.
+type evalAssertion struct {
+	Contains   string `yaml:"contains"`
+	Regex      string `yaml:"regex"`
+	Equals     string `yaml:"equals"`
+	JSONSchema any    `yaml:"json_schema"`
+	Rubric     string `yaml:"rubric"`
+
+	re     *regexp.Regexp
+	schema *jsonschema.Schema
+}
+
+// String describes the assertion for tables and reports.
+func (a evalAssertion) String() string {
+	switch {
+	case a.Contains != "":
+		return fmt.Sprintf("contains %q", a.Contains)
+	case a.Regex != "":
+		return fmt.Sprintf("regex %s", a.Regex)
+	case a.Equals != "":
+		return fmt.Sprintf("equals %q", firstLine(a.Equals, 40))
+	case a.JSONSchema != nil:
+		return "json_schema"
+	}
+	return fmt.Sprintf("rubric %q", firstLine(a.Rubric, 40))
+}
+
+// loadEvalSuite reads and validates the suite at path, compiling its
+// regular expressions and schemas.
+func loadEvalSuite(path string) (*evalSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s evalSuite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(s.Cases) == 0 {
+		return nil, fmt.Errorf("%s has no cases", path)
+	}
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("case-%d", i+1)
+		}
+		if c.Prompt == "" {
+			return nil, fmt.Errorf("case %s: prompt is required", c.Name)
+		}
+		if c.System == "" {
+			c.System = s.System
+		}
+		for j := range c.Assert {
+			if err := c.Assert[j].compile(filepath.Dir(path)); err != nil {
+				return nil, fmt.Errorf("case %s, assertion %d: %w", c.Name, j+1, err)
+			}
+		}
+	}
+	return &s, nil
+}
+
+func (a *evalAssertion) compile(dir string) error {
+	set := 0
+	for _, ok := range []bool{a.Contains != "", a.Regex != "", a.Equals != "", a.JSONSchema != nil, a.Rubric != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("set exactly one of contains, regex, equals, json_schema or rubric")
+	}
+	if a.Regex != "" {
+		re, err := regexp.Compile(a.Regex)
+		if err != nil {
+			return err
+		}
+		a.re = re
+	}
+	if a.JSONSchema != nil {
+		doc := a.JSONSchema
+		if path, ok := doc.(string); ok {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, &doc); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		// Round-trip through JSON so YAML integers become JSON numbers.
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("json_schema: %w", err)
+		}
+		doc, err = jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+		if err != nil {
+			return fmt.Errorf("json_schema: %w", err)
+		}
+		c := jsonschema.NewCompiler()
+		if err := c.AddResource("schema.json", doc); err != nil {
+			return fmt.Errorf("json_schema: %w", err)
+		}
+		if a.schema, err = c.Compile("schema.json"); err != nil {
+			return fmt.Errorf("json_schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// evalCheck is the outcome of one assertion.
+type evalCheck struct {
+	Assertion string `json:"assertion"`
+	Pass      bool   `json:"pass"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// evalResult is the outcome of one case against one target.
+type evalResult struct {
+	Case         string      `json:"case"`
+	Target       string      `json:"target"`
+	Model        string      `json:"model"`
+	Pass         bool        `json:"pass"`
+	Output       string      `json:"output"`
+	Checks       []evalCheck `json:"checks"`
+	InputTokens  int64       `json:"input_tokens,omitempty"`
+	OutputTokens int64       `json:"output_tokens,omitempty"`
+	LatencyMS    int64       `json:"latency_ms"`
+	Error        string      `json:"error,omitempty"`
+}
+
+// evalReport is the JSON report written by --report.
+type evalReport struct {
+	Suite   string       `json:"suite"`
+	Started time.Time    `json:"started"`
+	Targets []string     `json:"targets"`
+	Judge   string       `json:"judge,omitempty"`
+	Passed  int          `json:"passed"`
+	Total   int          `json:"total"`
+	Results []evalResult `json:"results"`
+}
+
+// evalTarget is a resolved target with its request defaults.
+type evalTarget struct {
+	compareTarget
+	provider ask.Provider
+	apiKey   string
+	baseURL  string
+}
+
+// newEvalTarget resolves item ("" for the configured default) and its credentials.
+func newEvalTarget(item string) (evalTarget, error) {
+	t, err := resolveTarget("", item)
+	if err != nil {
+		return evalTarget{}, err
+	}
+	p, err := ask.Get(t.Provider)
+	if err != nil {
+		return evalTarget{}, err
+	}
+	pcfg := cfg.ForProvider(p.Name())
+	apiKey, err := pcfg.RequireAPIKey(p)
+	if err != nil {
+		return evalTarget{}, fmt.Errorf("%s: %w", t, err)
+	}
+	return evalTarget{compareTarget: t, provider: p, apiKey: apiKey, baseURL: pcfg.BaseURL}, nil
+}
+
+// ask sends prompt to the target and returns the collected result.
+func (t evalTarget) ask(ctx context.Context, system, prompt string) (ask.Result, error) {
+	req := ask.UserRequest(prompt)
+	req.System = system
+	req.Model = t.Model
+	req.APIKey = t.apiKey
+	req.BaseURL = t.baseURL
+	return ask.Collect(t.provider.Run(ctx, req), nil)
+}
+
+// runEval runs the suite at path and prints the results.
+func runEval(ctx context.Context, path string) error {
+	suite, err := loadEvalSuite(path)
+	if err != nil {
+		return err
+	}
+
+	items := suite.Targets
+	if len(items) == 0 {
+		items = []string{""}
+	}
+	var targets []evalTarget
+	for _, item := range items {
+		t, err := newEvalTarget(item)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, t)
+	}
+
+	var judge *evalTarget
+	if name := cmp.Or(evalJudge, suite.Judge); name != "" {
+		t, err := newEvalTarget(name)
+		if err != nil {
+			return fmt.Errorf("judge: %w", err)
+		}
+		judge = &t
+	}
+	for _, c := range suite.Cases {
+		for _, a := range c.Assert {
+			if a.Rubric != "" && judge == nil {
+				return fmt.Errorf("case %s has a rubric assertion but no judge (set judge in the suite or --judge)", c.Name)
+			}
+		}
+	}
+
+	report := evalReport{Suite: path, Started: time.Now().UTC()}
+	for _, t := range targets {
+		report.Targets = append(report.Targets, t.String())
+	}
+	if judge != nil {
+		report.Judge = judge.String()
+	}
+
+	report.Results = make([]evalResult, len(suite.Cases)*len(targets))
+	prog := newBatchProgress(len(report.Results))
+	sem := make(chan struct{}, max(evalConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, c := range suite.Cases {
+		for j, t := range targets {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(idx int, c evalCase, t evalTarget) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				r := runEvalCase(ctx, c, t, judge)
+				report.Results[idx] = r
+				prog.add(!r.Pass)
+			}(i*len(targets)+j, c, t)
+		}
+	}
+	wg.Wait()
+	prog.finish()
+
+	for _, r := range report.Results {
+		report.Total++
+		if r.Pass {
+			report.Passed++
+		}
+	}
+	printEvalTable(suite, report)
+
+	if evalReportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(evalReportPath, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Report written to %s\n", evalReportPath)
+	}
+	if report.Passed < report.Total {
+		return fmt.Errorf("%d of %d evals failed", report.Total-report.Passed, report.Total)
+	}
+	return nil
+}
+
+// runEvalCase asks t the case's prompt and checks the answer.
+func runEvalCase(ctx context.Context, c evalCase, t evalTarget, judge *evalTarget) evalResult {
+	r := evalResult{Case: c.Name, Target: t.String(), Model: t.provider.ResolveModel(t.Model)}
+	start := time.Now()
+	res, err := t.ask(ctx, c.System, c.Prompt)
+	r.LatencyMS = time.Since(start).Milliseconds()
+	r.Output = res.Text
+	r.InputTokens, r.OutputTokens = res.Usage.InputTokens, res.Usage.OutputTokens
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	r.Pass = true
+	for _, a := range c.Assert {
+		check := evalCheck{Assertion: a.String()}
+		check.Pass, check.Reason = a.check(ctx, c.Prompt, res.Text, judge)
+		r.Pass = r.Pass && check.Pass
+		r.Checks = append(r.Checks, check)
+	}
+	return r
+}
+
+// check runs the assertion on output, returning whether it passed and why not.
+func (a evalAssertion) check(ctx context.Context, prompt, output string, judge *evalTarget) (bool, string) {
+	switch {
+	case a.Contains != "":
+		if strings.Contains(output, a.Contains) {
+			return true, ""
+		}
+		return false, "not found in output"
+	case a.re != nil:
+		if a.re.MatchString(output) {
+			return true, ""
+		}
+		return false, "no match in output"
+	case a.Equals != "":
+		if strings.TrimSpace(output) == strings.TrimSpace(a.Equals) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("got %q", firstLine(strings.TrimSpace(output), 60))
+	case a.schema != nil:
+		inst, err := jsonschema.UnmarshalJSON(strings.NewReader(jsonBody(output)))
+		if err != nil {
+			return false, "output is not JSON: " + err.Error()
+		}
+		if err := a.schema.Validate(inst); err != nil {
+			var verr *jsonschema.ValidationError
+			if errors.As(err, &verr) {
+				return false, strings.Join(strings.Fields(verr.Error()), " ")
+			}
+			return false, err.Error()
+		}
+		return true, ""
+	}
+	return judgeRubric(ctx, *judge, a.Rubric, prompt, output)
+}
+
+// jsonBody strips a markdown code fence around a JSON answer.
+func jsonBody(output string) string {
+	s := strings.TrimSpace(output)
+	if rest, ok := strings.CutPrefix(s, "```"); ok {
+		if _, body, ok := strings.Cut(rest, "\n"); ok {
+			s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(body), "```"))
+		}
+	}
+	return s
+}
+
+const judgePrompt = `You are grading an AI assistant's answer against a rubric.
+
+Rubric:
+%s
+
+Question:
+%s
+
+Answer:
+%s
+
+Reply with PASS or FAIL on the first line, then one sentence explaining why.`
+
+// judgeRubric asks the judge whether output meets rubric.
+func judgeRubric(ctx context.Context, judge evalTarget, rubric, prompt, output string) (bool, string) {
+	res, err := judge.ask(ctx, "", fmt.Sprintf(judgePrompt, rubric, prompt, output))
+	if err != nil {
+		return false, "judge: " + err.Error()
+	}
+	verdict, reason, _ := strings.Cut(strings.TrimSpace(res.Text), "\n")
+	reason = strings.TrimSpace(reason)
+	switch word := strings.Trim(strings.ToUpper(strings.TrimSpace(verdict)), "*:."); {
+	case strings.HasPrefix(word, "PASS"):
+		return true, reason
+	case strings.HasPrefix(word, "FAIL"):
+		if reason == "" {
+			reason = "judge says FAIL"
+		}
+		return false, reason
+	}
+	return false, fmt.Sprintf("judge reply not understood: %q", firstLine(res.Text, 60))
+}
+
+// printEvalTable prints a case × target grid of results, then the
+// failed checks and a summary.
+func printEvalTable(suite *evalSuite, report evalReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "CASE\t%s\n", strings.Join(report.Targets, "\t"))
+	for i, c := range suite.Cases {
+		cells := []string{c.Name}
+		for j := range report.Targets {
+			r := report.Results[i*len(report.Targets)+j]
+			cell := "PASS"
+			switch {
+			case r.Error != "":
+				cell = "ERROR"
+			case !r.Pass:
+				cell = "FAIL"
+			}
+			cells = append(cells, fmt.Sprintf("%s %.1fs", cell, float64(r.LatencyMS)/1000))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+
+	for _, r := range report.Results {
+		if r.Pass {
+			continue
+		}
+		fmt.Printf("\n%s · %s\n", r.Case, r.Target)
+		if r.Error != "" {
+			fmt.Printf("  error: %s\n", r.Error)
+		}
+		for _, c := range r.Checks {
+			if !c.Pass {
+				fmt.Printf("  ✗ %s: %s\n", c.Assertion, c.Reason)
+			}
+		}
+	}
+	fmt.Printf("\n%d/%d passed\n", report.Passed, report.Total)
+}
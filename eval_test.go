@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvalAssertionCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		assert evalAssertion
+		output string
+		want   bool
+	}{
+		{"contains", evalAssertion{Contains: "Paris"}, "It is Paris.", true},
+		{"contains missing", evalAssertion{Contains: "Paris"}, "It is Lyon.", false},
+		{"regex", evalAssertion{Regex: `(?i)\bparis\b`}, "PARIS", true},
+		{"regex missing", evalAssertion{Regex: `^\d+$`}, "42 apples", false},
+		{"equals trims", evalAssertion{Equals: "4"}, " 4\n", true},
+		{"equals differs", evalAssertion{Equals: "4"}, "four", false},
+		{"schema", evalAssertion{JSONSchema: map[string]any{"type": "object", "required": []any{"name"}}}, `{"name": "Ada"}`, true},
+		{"schema fenced", evalAssertion{JSONSchema: map[string]any{"type": "array", "minItems": 2}}, "```json\n[1, 2]\n```", true},
+		{"schema invalid", evalAssertion{JSONSchema: map[string]any{"type": "object", "required": []any{"name"}}}, `{"born": 1815}`, false},
+		{"schema not json", evalAssertion{JSONSchema: map[string]any{"type": "object"}}, "Sure! Here it is.", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.assert.compile("."); err != nil {
+				t.Fatal(err)
+			}
+			if got, reason := tt.assert.check(context.Background(), "", tt.output, nil); got != tt.want {
+				t.Errorf("check(%q) = %v (%s), want %v", tt.output, got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalAssertionCompile(t *testing.T) {
+	for _, a := range []evalAssertion{
+		{},
+		{Contains: "a", Regex: "b"},
+		{Regex: "("},
+		{JSONSchema: "missing.json"},
+	} {
+		if err := a.compile(t.TempDir()); err == nil {
+			t.Errorf("compile(%+v) succeeded", a)
+		}
+	}
+}
+
+func TestCLIEval(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"script.json": `[
+			{"match": "grading", "text": "PASS\nIt names Paris."},
+			{"match": "capital of France", "text": "Paris."},
+			{"match": "Ada", "text": "{\"name\": \"Ada Lovelace\"}"}
+		]`,
+		"person.schema.json": `{"type": "object", "required": ["name", "born"]}`,
+		"suite.yaml": `targets: [mock]
+judge: mock:mock
+cases:
+  - name: capital
+    prompt: What is the capital of France?
+    assert:
+      - contains: Paris
+      - rubric: Names Paris.
+  - name: person
+    prompt: Return Ada Lovelace as JSON.
+    assert:
+      - json_schema: person.schema.json
+`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := `{"mode": "api", "provider": "mock", "mock_script": "` + filepath.Join(dir, "script.json") + `"}`
+
+	out, stderr, err := runAsk(t, config, dir, "eval", "suite.yaml", "--report", "report.json")
+	if err == nil || !strings.Contains(stderr, "1 of 2 evals failed") {
+		t.Fatalf("err = %v, stderr %q", err, stderr)
+	}
+	for _, want := range []string{"capital   PASS", "person    FAIL", `missing property 'born'`, "1/2 passed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report evalReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed != 1 || report.Total != 2 || report.Judge != "mock:mock" {
+		t.Errorf("report = %+v", report)
+	}
+	if r := report.Results[0]; !r.Pass || r.Output != "Paris." || len(r.Checks) != 2 || r.Checks[1].Reason != "It names Paris." {
+		t.Errorf("capital result = %+v", r)
+	}
+}
@@ -1,86 +1,34 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/laurensent/ask/pkg/history"
 )
 
-const timeFormat = "2006-01-02 15:04:05"
-
-func historyPath() string {
-	return filepath.Join(dataDir(), "history")
-}
-
-// saveHistory appends a query to the history file.
-func saveHistory(query string) {
-	if query == "" {
-		return
-	}
-	p := historyPath()
-	_ = os.MkdirAll(filepath.Dir(p), 0755)
-	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return
-	}
-	defer f.Close()
-	escaped := strings.ReplaceAll(query, "\\", "\\\\")
-	escaped = strings.ReplaceAll(escaped, "\n", "\\n")
-	escaped = strings.ReplaceAll(escaped, "\t", "\\t")
-	fmt.Fprintf(f, "%s\t%s\n", time.Now().Format(timeFormat), escaped)
-}
-
-type historyEntry struct {
-	Time  string
-	Query string
-}
-
-func loadHistory() ([]historyEntry, error) {
-	f, err := os.Open(historyPath())
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	defer f.Close()
-
-	var entries []historyEntry
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		query := parts[1]
-		query = strings.ReplaceAll(query, "\\t", "\t")
-		query = strings.ReplaceAll(query, "\\n", "\n")
-		query = strings.ReplaceAll(query, "\\\\", "\\")
-		entries = append(entries, historyEntry{Time: parts[0], Query: query})
-	}
-	return entries, scanner.Err()
-}
-
 // --- bubbletea history browser ---
 
 // historyItem implements list.Item for the bubbles/list component.
 type historyItem struct {
-	entry historyEntry
+	entry history.Entry
 }
 
 func (i historyItem) Title() string       { return firstLine(i.entry.Query, 80) }
-func (i historyItem) Description() string { return i.entry.Time }
 func (i historyItem) FilterValue() string { return i.entry.Query }
 
+func (i historyItem) Description() string {
+	if i.entry.SessionID != "" {
+		return i.entry.Time + " · resumable (r)"
+	}
+	return i.entry.Time
+}
+
 var (
 	titleStyle = lipgloss.NewStyle().
 			Bold(true).
@@ -90,7 +38,8 @@ var (
 
 type historyBrowser struct {
 	list     list.Model
-	selected *historyEntry
+	selected *history.Entry
+	resume   bool // continue the selected entry's session instead of re-running it
 }
 
 func (m historyBrowser) Init() tea.Cmd {
@@ -113,6 +62,13 @@ func (m historyBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyEsc, tea.KeyCtrlC:
 			return m, tea.Quit
 		}
+		if msg.String() == "r" {
+			if item, ok := m.list.SelectedItem().(historyItem); ok && item.entry.SessionID != "" {
+				m.selected = &item.entry
+				m.resume = true
+				return m, tea.Quit
+			}
+		}
 	case tea.WindowSizeMsg:
 		m.list.SetSize(msg.Width, msg.Height)
 	}
@@ -128,7 +84,7 @@ func (m historyBrowser) View() string {
 
 // interactiveHistory opens an interactive list for browsing and re-running past queries.
 func interactiveHistory() error {
-	entries, err := loadHistory()
+	entries, err := history.Load()
 	if err != nil {
 		return fmt.Errorf("failed to read history: %w", err)
 	}
@@ -155,6 +111,9 @@ func interactiveHistory() error {
 	l.SetFilteringEnabled(true)
 	l.SetShowStatusBar(true)
 	l.SetStatusBarItemName("query", "queries")
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "resume session"))}
+	}
 
 	m := historyBrowser{list: l}
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
@@ -168,18 +127,25 @@ func interactiveHistory() error {
 		return nil
 	}
 
-	saveHistory(final.selected.Query)
+	if final.resume {
+		prompt, err := readInteractivePrompt()
+		if err != nil || prompt == "" {
+			return nil
+		}
+		return runCLIWithHistory(prompt, model, final.selected.SessionID)
+	}
+
 	if cfg.Mode == "api" {
+		history.Add(final.selected.Query, "")
 		return runAPI(final.selected.Query, model, cfg)
 	}
-	return runClaude(final.selected.Query, model)
+	return runCLIWithHistory(final.selected.Query, model, "")
 }
 
 // clearHistory removes the history file.
 func clearHistory() error {
-	p := historyPath()
-	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to clear history: %w", err)
+	if err := history.Clear(); err != nil {
+		return err
 	}
 	fmt.Println("History cleared.")
 	return nil
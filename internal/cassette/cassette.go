@@ -0,0 +1,218 @@
+// Package cassette replays recorded HTTP exchanges with provider APIs from
+// testdata files, so tests run the real SDK code paths without network
+// access. With ASK_RECORD=1 set, it proxies to the real API instead and
+// rewrites the cassette.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Request     json.RawMessage `json:"request,omitempty"` // request body, when it is JSON
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Response    string          `json:"response"` // response body; SSE streams are kept whole
+}
+
+// Cassette is the file format: the interactions of one test, in order.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Load reads the cassette at path.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes c to path, creating its directory if needed.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Recording reports whether cassettes are being re-recorded.
+func Recording() bool { return os.Getenv("ASK_RECORD") == "1" }
+
+// Server starts a test server for the cassette at path and returns its
+// URL, to be used as the provider's base URL. When replaying, requests
+// must arrive in the recorded order with the recorded method, path and
+// JSON body.
+// When recording, they are forwarded to upstream (such as
+// https://api.openai.com/v1) and the cassette is written when t ends.
+func Server(t testing.TB, path, upstream string) string {
+	t.Helper()
+	var h http.Handler
+	if Recording() {
+		rec := &recorder{upstream: strings.TrimSuffix(upstream, "/")}
+		t.Cleanup(func() {
+			if err := rec.cassette.Save(path); err != nil {
+				t.Errorf("saving cassette: %v", err)
+			}
+		})
+		h = rec
+	} else {
+		c, err := Load(path)
+		if err != nil {
+			t.Fatalf("loading cassette: %v (record it with ASK_RECORD=1)", err)
+		}
+		h = &player{t: t, cassette: c}
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// player serves the interactions of a cassette in order.
+type player struct {
+	t        testing.TB
+	mu       sync.Mutex
+	cassette *Cassette
+	next     int
+}
+
+func (p *player) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next >= len(p.cassette.Interactions) {
+		p.t.Errorf("cassette: unexpected request %s %s", r.Method, r.URL.Path)
+		http.Error(w, "no more recorded interactions", http.StatusTeapot)
+		return
+	}
+	in := p.cassette.Interactions[p.next]
+	p.next++
+	if path, _, _ := strings.Cut(in.Path, "?"); r.Method != in.Method || r.URL.Path != path {
+		p.t.Errorf("cassette: got %s %s, recorded %s %s", r.Method, r.URL.Path, in.Method, in.Path)
+	}
+	if len(in.Request) > 0 {
+		body, _ := io.ReadAll(r.Body)
+		if !sameJSON(body, in.Request) {
+			p.t.Errorf("cassette: %s %s request body differs from the recording\ngot:      %s\nrecorded: %s", r.Method, r.URL.Path, compact(body), compact(in.Request))
+		}
+	}
+	if in.ContentType != "" {
+		w.Header().Set("Content-Type", in.ContentType)
+	}
+	w.WriteHeader(in.Status)
+	io.WriteString(w, in.Response)
+}
+
+// sameJSON reports whether a and b are equal JSON documents, ignoring
+// formatting and key order.
+func sameJSON(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// compact returns data without insignificant whitespace, or as is if it
+// isn't JSON.
+func compact(data []byte) string {
+	var buf bytes.Buffer
+	if json.Compact(&buf, data) != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// recorder forwards requests to upstream and records the exchanges.
+type recorder struct {
+	upstream string
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// forwardHeaders are the request headers passed upstream. Their values,
+// which include credentials, are never written to the cassette.
+var forwardHeaders = []string{"Authorization", "X-Api-Key", "X-Goog-Api-Key", "Anthropic-Version", "Anthropic-Beta", "Content-Type", "Accept"}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, rec.upstream+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	for _, k := range forwardHeaders {
+		if v := r.Header.Get(k); v != "" {
+			req.Header.Set(k, v)
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	in := Interaction{
+		Method:      r.Method,
+		Path:        stripKey(r.URL),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Response:    string(respBody),
+	}
+	if json.Valid(body) {
+		in.Request = body
+	}
+	rec.mu.Lock()
+	rec.cassette.Interactions = append(rec.cassette.Interactions, in)
+	rec.mu.Unlock()
+
+	if in.ContentType != "" {
+		w.Header().Set("Content-Type", in.ContentType)
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(respBody)
+}
+
+// stripKey returns the path and query of u without a "key" parameter,
+// which some APIs accept in place of a header.
+func stripKey(u *url.URL) string {
+	path, query, ok := strings.Cut(u.RequestURI(), "?")
+	if !ok {
+		return path
+	}
+	var kept []string
+	for _, kv := range strings.Split(query, "&") {
+		if !strings.HasPrefix(kv, "key=") {
+			kept = append(kept, kv)
+		}
+	}
+	if len(kept) == 0 {
+		return path
+	}
+	return path + "?" + strings.Join(kept, "&")
+}
@@ -0,0 +1,92 @@
+package cassette
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			http.Error(w, "no key", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"text\":\"hi\"}\n\n")
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "c.json")
+	post := func(url string) string {
+		req, _ := http.NewRequest("POST", url+"/v1/messages?key=secret&alt=sse", strings.NewReader(`{"model":"m"}`))
+		req.Header.Set("X-Api-Key", "secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return string(data)
+	}
+
+	t.Run("record", func(t *testing.T) {
+		t.Setenv("ASK_RECORD", "1")
+		if got := post(Server(t, path, upstream.URL)); got != "data: {\"text\":\"hi\"}\n\n" {
+			t.Errorf("proxied body = %q", got)
+		}
+	})
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := c.Interactions[0]
+	if in.Path != "/v1/messages?alt=sse" || !strings.Contains(string(in.Request), `"model": "m"`) || in.Status != 200 {
+		t.Errorf("recorded %+v", in)
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		upstream.Close()
+		if got := post(Server(t, path, "")); got != "data: {\"text\":\"hi\"}\n\n" {
+			t.Errorf("replayed body = %q", got)
+		}
+	})
+}
+
+// errorRecorder collects Errorf calls instead of failing the test.
+type errorRecorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestReplayComparesBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	c := Cassette{Interactions: []Interaction{
+		{Method: "POST", Path: "/v1/messages", Request: []byte(`{"model": "m", "max_tokens": 10}`), Status: 200, Response: "ok"},
+		{Method: "POST", Path: "/v1/messages", Request: []byte(`{"model": "m"}`), Status: 200, Response: "ok"},
+	}}
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	rec := &errorRecorder{TB: t}
+	url := Server(rec, path, "")
+	for _, body := range []string{`{"max_tokens":10,"model":"m"}`, `{"model":"m","tools":[]}`} {
+		resp, err := http.Post(url+"/v1/messages", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if len(rec.errs) != 1 || !strings.Contains(rec.errs[0], `"tools":[]`) {
+		t.Errorf("errors = %q, want one for the second body", rec.errs)
+	}
+}
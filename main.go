@@ -11,23 +11,34 @@ var knownSubcommands = map[string]bool{
 	"history": true, "h": true,
 	"config": true,
 	"models": true,
+	"chat":   true,
+	"batch":  true,
+	"mcp":    true,
+	"serve":  true,
+	"eval":   true,
+	"cache":  true,
 	"help":   true,
 }
 
 // flagsWithValue lists ask flags that consume the next argument as a value.
 var flagsWithValue = map[string]bool{
 	"-m": true, "--model": true,
+	"-f": true, "--file": true,
+	"--compare": true, "--truncate": true, "--resume": true, "--cache-ttl": true, "--prompt-cache": true,
 }
 
 // knownBoolFlags lists ask boolean flags that do not consume a value argument.
 var knownBoolFlags = map[string]bool{
 	"--raw": true, "--dry-run": true,
-	"--think": true, "--search": true,
+	"--think": true, "--search": true, "--chunk": true, "--sources-only": true, "--agent": true, "--no-cache": true,
+	"-i": true, "--interactive": true,
+	"-e": true, "--editor": true,
+	"-c": true, "--continue": true,
 	"-h": true, "--help": true,
 	"-v": true, "--version": true,
 }
 
-// passthrough collects unknown flags to forward to the claude CLI.
+// passthrough collects unknown flags to forward to the CLI backend.
 var passthrough []string
 
 func main() {
@@ -72,7 +83,7 @@ func isKnownBoolFlagWithValue(arg string) bool {
 // reorderArgs extracts known ask flags, passthrough claude flags, and
 // positional arguments (prompt words) from the argument list.
 // ask flags go before "--", positional args go after.
-// Unknown flags are collected in the passthrough global for forwarding to claude.
+// Unknown flags are collected in the passthrough global for forwarding to the CLI backend.
 func reorderArgs(args []string) []string {
 	var flags []string
 	var positional []string
@@ -69,6 +69,18 @@ func TestReorderArgs(t *testing.T) {
 			[]string{"-m", "opus", "--raw"},
 			nil,
 		},
+		{
+			"continue and resume are ask flags",
+			[]string{"-c", "and", "--resume", "abc", "then"},
+			[]string{"-c", "--resume", "abc", "--", "and", "then"},
+			nil,
+		},
+		{
+			"file takes a value",
+			[]string{"summarize", "-f", "report.pdf", "--file", "chart.png"},
+			[]string{"-f", "report.pdf", "--file", "chart.png", "--", "summarize"},
+			nil,
+		},
 		{
 			"only positional",
 			[]string{"hello", "world"},
@@ -155,3 +167,22 @@ func TestBuildPrompt(t *testing.T) {
 		})
 	}
 }
+
+func TestPipePrefixLen(t *testing.T) {
+	tests := []struct {
+		args        []string
+		pipeContent string
+		want        string // the prefix
+	}{
+		{[]string{"how", "to", "rebase"}, "", ""},
+		{nil, "some data", "Here is some data. Please analyze it:\n\n```\nsome data\n```"},
+		{[]string{"review"}, "diff output", "Here is the input data:\n\n```\ndiff output\n```\n\n"},
+		{[]string{"and now?"}, "a\n```\n\nb", "Here is the input data:\n\n```\na\n```\n\nb\n```\n\n"},
+	}
+	for _, tt := range tests {
+		prompt := buildPrompt(tt.args, tt.pipeContent)
+		if got := prompt[:pipePrefixLen(prompt)]; got != tt.want {
+			t.Errorf("prefix of %q = %q, want %q", prompt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+const mcpConnectTimeout = 20 * time.Second
+
+// mcpTransportLabel describes how ask reaches the server, for listings.
+func mcpTransportLabel(sc config.MCPServer) string {
+	if sc.Command != "" {
+		return "stdio: " + formatCommand(sc.Command, sc.Args)
+	}
+	return "http: " + sc.URL
+}
+
+// mcpTool exposes a tool of an MCP server to the model as
+// mcp__<server>__<tool>.
+type mcpTool struct {
+	client *mcpClient
+	info   mcpToolInfo
+	trust  bool
+}
+
+var unsafeToolChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// mcpToolName builds the function name the model sees. Provider APIs accept
+// at most 64 characters from [a-zA-Z0-9_-].
+func mcpToolName(server, tool string) string {
+	name := "mcp__" + unsafeToolChars.ReplaceAllString(server, "_") + "__" + unsafeToolChars.ReplaceAllString(tool, "_")
+	return name[:min(len(name), 64)]
+}
+
+func (t mcpTool) Name() string        { return mcpToolName(t.client.name, t.info.Name) }
+func (t mcpTool) Description() string { return t.info.Description }
+func (t mcpTool) Trusted() bool       { return t.trust }
+
+// Parameters returns the server's input schema, filled out to the object
+// shape every provider accepts.
+func (t mcpTool) Parameters() map[string]any {
+	schema := map[string]any{}
+	for k, v := range t.info.InputSchema {
+		if k != "$schema" {
+			schema[k] = v
+		}
+	}
+	schema["type"] = "object"
+	if _, ok := schema["properties"]; !ok {
+		schema["properties"] = map[string]any{}
+	}
+	if req, ok := schema["required"].([]any); ok {
+		var names []string
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				names = append(names, s)
+			}
+		}
+		schema["required"] = names
+	}
+	return schema
+}
+
+func (t mcpTool) Run(ctx context.Context, args json.RawMessage) (string, error) {
+	text, isError, err := t.client.callTool(ctx, t.info.Name, args)
+	if err != nil {
+		return "", err
+	}
+	if isError {
+		return "", errors.New(text)
+	}
+	return capOutput(text, maxToolOutput), nil
+}
+
+// mcpSession holds the connected servers for one run.
+type mcpSession struct {
+	clients []*mcpClient
+	tools   []ask.Tool
+}
+
+// connectMCPServers connects to every configured server and collects its
+// tools. Servers that fail are reported on stderr and skipped.
+func connectMCPServers(ctx context.Context, servers map[string]config.MCPServer) *mcpSession {
+	s := &mcpSession{}
+	for _, name := range sortedKeys(servers) {
+		sc := servers[name]
+		c, tools, err := connectAndList(ctx, name, sc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v (skipped)\n", err)
+			continue
+		}
+		s.clients = append(s.clients, c)
+		for _, info := range tools {
+			s.tools = append(s.tools, mcpTool{client: c, info: info, trust: sc.Trust})
+		}
+	}
+	return s
+}
+
+// connectAndList connects to one server and lists its tools.
+func connectAndList(ctx context.Context, name string, sc config.MCPServer) (*mcpClient, []mcpToolInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, mcpConnectTimeout)
+	defer cancel()
+	c, err := connectMCP(ctx, name, sc)
+	if err != nil {
+		return nil, nil, err
+	}
+	tools, err := c.listTools(ctx)
+	if err != nil {
+		c.close()
+		return nil, nil, fmt.Errorf("mcp server %s: tools/list: %w", name, err)
+	}
+	return c, tools, nil
+}
+
+func (s *mcpSession) close() {
+	for _, c := range s.clients {
+		c.close()
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Manage MCP tool servers",
+	Long: `Work with the Model Context Protocol servers in the mcp_servers config.
+
+In API mode, ask connects to every configured server and lets the model
+call their tools (anthropic, openai-compatible and gemini providers).`,
+}
+
+var mcpListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show configured MCP servers and their tools",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(cfg.MCPServers) == 0 {
+			fmt.Printf("No MCP servers configured. Add mcp_servers to %s\n", config.Path())
+			return nil
+		}
+		for i, name := range sortedKeys(cfg.MCPServers) {
+			sc := cfg.MCPServers[name]
+			if i > 0 {
+				fmt.Println()
+			}
+			trust := ""
+			if sc.Trust {
+				trust = " (trusted)"
+			}
+			fmt.Printf("%s%s\n  %s\n", name, trust, mcpTransportLabel(sc))
+
+			c, tools, err := connectAndList(cmd.Context(), name, sc)
+			if err != nil {
+				fmt.Printf("  %s\n", wizardDim.Render("error: "+err.Error()))
+				continue
+			}
+			c.close()
+			if len(tools) == 0 {
+				fmt.Println("  (no tools)")
+			}
+			for _, t := range tools {
+				fmt.Printf("  • %s", t.Name)
+				if t.Description != "" {
+					fmt.Printf(" %s", wizardDim.Render("— "+firstLine(strings.TrimSpace(t.Description), 70)))
+				}
+				fmt.Println()
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpListCmd)
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
+)
+
+// mcpProtocolVersion is the MCP revision ask speaks.
+const mcpProtocolVersion = "2025-06-18"
+
+// rpcMessage is a JSON-RPC 2.0 request, notification or response.
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return fmt.Sprintf("%s (code %d)", e.Message, e.Code) }
+
+// mcpTransport sends JSON-RPC messages to a server and returns the
+// response to requests.
+type mcpTransport interface {
+	roundTrip(ctx context.Context, msg rpcMessage) (*rpcMessage, error)
+	close() error
+}
+
+// mcpClient is a connection to one MCP server.
+type mcpClient struct {
+	name      string
+	transport mcpTransport
+	mu        sync.Mutex
+	nextID    int64
+}
+
+// mcpToolInfo is a tool advertised by an MCP server.
+type mcpToolInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// connectMCP starts or dials the server and performs the initialize handshake.
+func connectMCP(ctx context.Context, name string, sc config.MCPServer) (*mcpClient, error) {
+	var t mcpTransport
+	var err error
+	switch {
+	case sc.Command != "":
+		t, err = newStdioTransport(sc)
+	case sc.URL != "":
+		t = &httpTransport{url: sc.URL, headers: sc.Headers}
+	default:
+		return nil, fmt.Errorf("mcp_servers: %s: set command or url", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s: %w", name, err)
+	}
+
+	c := &mcpClient{name: name, transport: t}
+	err = c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "ask", "version": version},
+	}, nil)
+	if err == nil {
+		err = c.notify(ctx, "notifications/initialized")
+	}
+	if err != nil {
+		t.close()
+		return nil, fmt.Errorf("mcp server %s: %w", name, err)
+	}
+	return c, nil
+}
+
+// call sends a request and decodes its result into out (if non-nil).
+func (c *mcpClient) call(ctx context.Context, method string, params, out any) error {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.mu.Unlock()
+
+	msg := rpcMessage{JSONRPC: "2.0", ID: json.RawMessage(fmt.Sprint(id)), Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = data
+	}
+	resp, err := c.transport.roundTrip(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if out != nil {
+		return json.Unmarshal(resp.Result, out)
+	}
+	return nil
+}
+
+// notify sends a notification, which has no response.
+func (c *mcpClient) notify(ctx context.Context, method string) error {
+	_, err := c.transport.roundTrip(ctx, rpcMessage{JSONRPC: "2.0", Method: method})
+	return err
+}
+
+// listTools returns every tool the server offers, following pagination.
+func (c *mcpClient) listTools(ctx context.Context) ([]mcpToolInfo, error) {
+	var tools []mcpToolInfo
+	cursor := ""
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var page struct {
+			Tools      []mcpToolInfo `json:"tools"`
+			NextCursor string        `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, err
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// callTool runs a tool and returns its text content. isError reports a
+// tool-level failure, which is passed on to the model rather than aborting.
+func (c *mcpClient) callTool(ctx context.Context, name string, args json.RawMessage) (text string, isError bool, err error) {
+	var result struct {
+		Content []struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			MimeType string `json:"mimeType"`
+			Resource struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"resource"`
+		} `json:"content"`
+		StructuredContent json.RawMessage `json:"structuredContent"`
+		IsError           bool            `json:"isError"`
+	}
+	err = c.call(ctx, "tools/call", map[string]any{"name": name, "arguments": ask.ToolArgs(args)}, &result)
+	if err != nil {
+		return "", false, err
+	}
+	var parts []string
+	for _, item := range result.Content {
+		switch item.Type {
+		case "text":
+			parts = append(parts, item.Text)
+		case "resource":
+			if item.Resource.Text != "" {
+				parts = append(parts, item.Resource.Text)
+			} else {
+				parts = append(parts, "[resource "+item.Resource.URI+"]")
+			}
+		default:
+			parts = append(parts, fmt.Sprintf("[%s %s]", item.Type, item.MimeType))
+		}
+	}
+	if len(parts) == 0 && len(result.StructuredContent) > 0 {
+		parts = append(parts, string(result.StructuredContent))
+	}
+	return strings.Join(parts, "\n"), result.IsError, nil
+}
+
+func (c *mcpClient) close() error { return c.transport.close() }
+
+// syncBuffer is a bytes.Buffer that the exec stderr copier can write to
+// while it is read.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// stdioTransport runs the server as a subprocess speaking newline-delimited
+// JSON-RPC on stdin and stdout.
+type stdioTransport struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr syncBuffer
+
+	mu      sync.Mutex
+	pending map[string]chan *rpcMessage
+	done    chan struct{}
+	err     error
+}
+
+func newStdioTransport(sc config.MCPServer) (*stdioTransport, error) {
+	cmd := exec.Command(sc.Command, sc.Args...)
+	cmd.Env = os.Environ()
+	for k, v := range sc.Env {
+		cmd.Env = append(cmd.Env, k+"="+os.ExpandEnv(v))
+	}
+	t := &stdioTransport{cmd: cmd, pending: map[string]chan *rpcMessage{}, done: make(chan struct{})}
+	cmd.Stderr = &t.stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	t.stdin = stdin
+	go t.readLoop(stdout)
+	return t, nil
+}
+
+// readLoop dispatches responses to their waiting requests. Requests from
+// the server (such as ping) get an empty result.
+func (t *stdioTransport) readLoop(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg rpcMessage
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil {
+			continue
+		}
+		if msg.Method != "" {
+			if msg.ID != nil {
+				t.write(rpcMessage{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage("{}")})
+			}
+			continue
+		}
+		t.mu.Lock()
+		ch := t.pending[string(msg.ID)]
+		delete(t.pending, string(msg.ID))
+		t.mu.Unlock()
+		if ch != nil {
+			ch <- &msg
+		}
+	}
+	t.mu.Lock()
+	t.err = fmt.Errorf("server exited")
+	if s := strings.TrimSpace(t.stderr.String()); s != "" {
+		t.err = fmt.Errorf("server exited: %s", firstLine(s, 200))
+	}
+	t.mu.Unlock()
+	close(t.done)
+}
+
+func (t *stdioTransport) write(msg rpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err = t.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (t *stdioTransport) roundTrip(ctx context.Context, msg rpcMessage) (*rpcMessage, error) {
+	var ch chan *rpcMessage
+	if msg.ID != nil {
+		ch = make(chan *rpcMessage, 1)
+		t.mu.Lock()
+		t.pending[string(msg.ID)] = ch
+		t.mu.Unlock()
+	}
+	if err := t.write(msg); err != nil {
+		t.forget(msg.ID)
+		select {
+		case <-t.done:
+			return nil, t.err
+		default:
+			return nil, err
+		}
+	}
+	if ch == nil {
+		return nil, nil
+	}
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-t.done:
+		return nil, t.err
+	case <-ctx.Done():
+		t.forget(msg.ID)
+		return nil, ctx.Err()
+	}
+}
+
+// forget drops the pending entry of a request that won't wait for its response.
+func (t *stdioTransport) forget(id json.RawMessage) {
+	t.mu.Lock()
+	delete(t.pending, string(id))
+	t.mu.Unlock()
+}
+
+func (t *stdioTransport) close() error {
+	t.stdin.Close()
+	t.cmd.Process.Kill()
+	return t.cmd.Wait()
+}
+
+// httpTransport talks to a Streamable HTTP server: each message is POSTed,
+// and the response comes back as JSON or as an SSE stream.
+type httpTransport struct {
+	url       string
+	headers   map[string]string
+	sessionID string
+}
+
+func (t *httpTransport) roundTrip(ctx context.Context, msg rpcMessage) (*rpcMessage, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", mcpProtocolVersion)
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		t.sessionID = id
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("HTTP %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if msg.ID == nil {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readSSEResponse(resp.Body, msg.ID)
+	}
+	var out rpcMessage
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &out, nil
+}
+
+// readSSEResponse returns the message with the given ID from an SSE stream.
+func readSSEResponse(r io.Reader, id json.RawMessage) (*rpcMessage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if rest, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(rest, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+		var msg rpcMessage
+		if json.Unmarshal([]byte(data.String()), &msg) == nil && string(msg.ID) == string(id) && msg.Method == "" {
+			return &msg, nil
+		}
+		data.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("stream ended without a response")
+}
+
+func (t *httpTransport) close() error { return nil }
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/spf13/cobra"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run ask as an MCP server on stdio",
+	Long: `Serve the Model Context Protocol on stdin and stdout, so other agents can
+ask any configured provider a question through ask. Tools:
+
+  ask_model       answer a prompt with one model (provider and model optional)
+  compare_models  answer a prompt with several models at once
+  list_models     show providers, whether they have a key, and their aliases
+
+Requests use the same providers, keys and defaults as the CLI.`,
+	Example: `  claude mcp add ask -- ask mcp serve`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return serveMCP(cmd.Context(), os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpServeCmd)
+}
+
+// mcpServerTool is a tool offered by ask mcp serve.
+type mcpServerTool struct {
+	info mcpToolInfo
+	run  func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+func mcpServerTools() []mcpServerTool {
+	return []mcpServerTool{
+		{mcpToolInfo{
+			Name:        "ask_model",
+			Description: "Ask a language model a question and return its answer. Leave provider and model empty to use the user's default.",
+			InputSchema: objectSchema(map[string]any{
+				"prompt":   stringProp("the question or task"),
+				"provider": stringProp("provider name, e.g. anthropic, openai, gemini, ollama"),
+				"model":    stringProp("model alias or ID, e.g. haiku, flash, gpt4o, or provider:model"),
+				"system":   stringProp("optional system prompt"),
+			}, "prompt"),
+		}, askModelTool},
+		{mcpToolInfo{
+			Name:        "compare_models",
+			Description: "Ask several models the same question at once and return each answer with its latency and token usage.",
+			InputSchema: objectSchema(map[string]any{
+				"prompt": stringProp("the question or task"),
+				"models": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"minItems":    2,
+					"description": `model aliases or provider:model pairs, e.g. ["haiku", "flash", "openai:gpt-4o-mini"]`,
+				},
+			}, "prompt", "models"),
+		}, compareModelsTool},
+		{mcpToolInfo{
+			Name:        "list_models",
+			Description: "List the providers ask can use, whether each has an API key, and its model aliases.",
+			InputSchema: objectSchema(map[string]any{}),
+		}, listModelsTool},
+	}
+}
+
+// askModelTool runs the ask_model tool.
+func askModelTool(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct{ Prompt, Provider, Model, System string }
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	target, err := resolveTarget(in.Provider, in.Model)
+	if err != nil {
+		return "", err
+	}
+	p, err := ask.Get(target.Provider)
+	if err != nil {
+		return "", err
+	}
+	pcfg := cfg.ForProvider(p.Name())
+	apiKey, err := pcfg.RequireAPIKey(p)
+	if err != nil {
+		return "", err
+	}
+
+	req := ask.UserRequest(in.Prompt)
+	req.System = in.System
+	req.Model = target.Model
+	req.APIKey = apiKey
+	req.BaseURL = pcfg.BaseURL
+	res, err := ask.Collect(p.Run(ctx, req), nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(res.Text) + ask.Footnotes(res.Sources), nil
+}
+
+// resolveTarget picks the provider and model for a request that may name
+// either or neither, falling back to the configured provider and default
+// model.
+func resolveTarget(provider, model string) (compareTarget, error) {
+	switch {
+	case provider != "":
+		p, err := ask.Get(provider)
+		if err != nil {
+			return compareTarget{}, err
+		}
+		if model == "" {
+			model = p.DefaultModel()
+			if provider == cfg.ResolvedProvider() && cfg.DefaultModel != "" {
+				model = cfg.DefaultModel
+			}
+		}
+		return compareTarget{Provider: provider, Model: model}, nil
+	case model != "":
+		return parseTarget(model, cfg.ResolvedProvider())
+	}
+	p, err := ask.Get(cfg.ResolvedProvider())
+	if err != nil {
+		return compareTarget{}, err
+	}
+	model = cfg.DefaultModel
+	if model == "" {
+		model = p.DefaultModel()
+	}
+	return compareTarget{Provider: p.Name(), Model: model}, nil
+}
+
+// compareModelsTool runs the compare_models tool.
+func compareModelsTool(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct {
+		Prompt string
+		Models []string
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	targets, err := parseCompareSpec(strings.Join(in.Models, ","), cfg.ResolvedProvider())
+	if err != nil {
+		return "", err
+	}
+	runs, err := newCompareRuns(targets, in.Prompt)
+	if err != nil {
+		return "", err
+	}
+	for ev := range startCompare(ctx, runs) {
+		runs[ev.idx].apply(ev)
+	}
+	var b strings.Builder
+	for _, r := range runs {
+		b.WriteString(r.markdown())
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// listModelsTool runs the list_models tool.
+func listModelsTool(ctx context.Context, args json.RawMessage) (string, error) {
+	var b strings.Builder
+	for _, name := range ask.Names() {
+		p, _ := ask.Lookup(name)
+		status := "ready"
+		if _, err := cfg.ForProvider(name).RequireAPIKey(p); err != nil {
+			status = "no API key"
+		}
+		fmt.Fprintf(&b, "%s (%s): default %s; aliases %s\n", name, status, p.DefaultModel(), strings.Join(p.ModelAliases(), ", "))
+	}
+	return b.String(), nil
+}
+
+// mcpServer answers JSON-RPC requests read line by line from in.
+type mcpServer struct {
+	tools []mcpServerTool
+	out   io.Writer
+
+	mu      sync.Mutex // guards out and cancels
+	cancels map[string]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// serveMCP runs the MCP server until in is closed. Tool calls run
+// concurrently; their responses may arrive out of order.
+func serveMCP(ctx context.Context, in io.Reader, out io.Writer) error {
+	s := &mcpServer{tools: mcpServerTools(), out: out, cancels: map[string]context.CancelFunc{}}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg rpcMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			s.send(rpcMessage{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: -32700, Message: "parse error"}})
+			continue
+		}
+		s.handle(ctx, msg)
+	}
+	s.wg.Wait()
+	return scanner.Err()
+}
+
+func (s *mcpServer) handle(ctx context.Context, msg rpcMessage) {
+	if msg.Method == "" {
+		return // a response; ask sends no requests to the client
+	}
+	if msg.ID == nil {
+		if msg.Method == "notifications/cancelled" {
+			var p struct {
+				RequestID json.RawMessage `json:"requestId"`
+			}
+			json.Unmarshal(msg.Params, &p)
+			s.mu.Lock()
+			if cancel := s.cancels[string(p.RequestID)]; cancel != nil {
+				cancel()
+			}
+			s.mu.Unlock()
+		}
+		return
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(msg.Params, &p)
+		// Revisions are dates, so an older client gets its own version back.
+		protocol := mcpProtocolVersion
+		if p.ProtocolVersion != "" && p.ProtocolVersion < mcpProtocolVersion {
+			protocol = p.ProtocolVersion
+		}
+		s.reply(msg.ID, map[string]any{
+			"protocolVersion": protocol,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "ask", "version": version},
+		})
+	case "ping":
+		s.reply(msg.ID, map[string]any{})
+	case "tools/list":
+		var tools []mcpToolInfo
+		for _, t := range s.tools {
+			tools = append(tools, t.info)
+		}
+		s.reply(msg.ID, map[string]any{"tools": tools})
+	case "tools/call":
+		s.startCall(ctx, msg)
+	default:
+		s.fail(msg.ID, rpcMethodNotFound, "method not found: "+msg.Method)
+	}
+}
+
+// startCall runs a tools/call request in the background. Tool failures
+// are returned as results with isError set, so the calling model sees them.
+func (s *mcpServer) startCall(ctx context.Context, msg rpcMessage) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		s.fail(msg.ID, rpcInvalidParams, err.Error())
+		return
+	}
+	var tool *mcpServerTool
+	for i := range s.tools {
+		if s.tools[i].info.Name == p.Name {
+			tool = &s.tools[i]
+		}
+	}
+	if tool == nil {
+		s.fail(msg.ID, rpcInvalidParams, "unknown tool: "+p.Name)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancels[string(msg.ID)] = cancel
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		text, err := tool.run(ctx, ask.ToolArgs(p.Arguments))
+		cancelled := ctx.Err() != nil
+		s.mu.Lock()
+		delete(s.cancels, string(msg.ID))
+		s.mu.Unlock()
+		cancel()
+		if cancelled {
+			return // the client expects no response
+		}
+		if err != nil {
+			text = err.Error()
+		}
+		s.reply(msg.ID, map[string]any{
+			"content": []any{map[string]any{"type": "text", "text": text}},
+			"isError": err != nil,
+		})
+	}()
+}
+
+func (s *mcpServer) reply(id json.RawMessage, result any) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.fail(id, -32603, err.Error())
+		return
+	}
+	s.send(rpcMessage{JSONRPC: "2.0", ID: id, Result: data})
+}
+
+func (s *mcpServer) fail(id json.RawMessage, code int, message string) {
+	s.send(rpcMessage{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}})
+}
+
+func (s *mcpServer) send(msg rpcMessage) {
+	data, _ := json.Marshal(msg)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
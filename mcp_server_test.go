@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/laurensent/ask/pkg/config"
+)
+
+func TestServeMCP(t *testing.T) {
+	var models []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Model string }
+		json.NewDecoder(r.Body).Decode(&body)
+		models = append(models, body.Model)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"content":"Paris"}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.Config{Mode: "api", Provider: "xai", APIKey: "key", BaseURL: srv.URL}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	go func() {
+		serveMCP(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	out := bufio.NewScanner(outR)
+	call := func(id int, method, params string) rpcMessage {
+		t.Helper()
+		fmt.Fprintf(inW, `{"jsonrpc":"2.0","id":%d,"method":%q,"params":%s}`+"\n", id, method, params)
+		if !out.Scan() {
+			t.Fatalf("%s: no response", method)
+		}
+		var msg rpcMessage
+		json.Unmarshal(out.Bytes(), &msg)
+		return msg
+	}
+
+	hello := call(1, "initialize", `{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test"}}`)
+	if !strings.Contains(string(hello.Result), `"protocolVersion":"2025-03-26"`) {
+		t.Errorf("initialize = %s", hello.Result)
+	}
+	fmt.Fprintln(inW, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	list := call(2, "tools/list", `{}`)
+	for _, name := range []string{"ask_model", "compare_models", "list_models"} {
+		if !strings.Contains(string(list.Result), `"name":"`+name+`"`) {
+			t.Errorf("tools/list is missing %s: %s", name, list.Result)
+		}
+	}
+
+	ask := call(3, "tools/call", `{"name":"ask_model","arguments":{"prompt":"capital of France?","model":"grok-3-mini"}}`)
+	if string(ask.ID) != "3" || !strings.Contains(string(ask.Result), `"text":"Paris"`) || !strings.Contains(string(ask.Result), `"isError":false`) {
+		t.Errorf("ask_model = %s", ask.Result)
+	}
+	if len(models) != 1 || models[0] != "grok-3-mini" {
+		t.Errorf("requested models %q", models)
+	}
+
+	bad := call(4, "tools/call", `{"name":"compare_models","arguments":{"prompt":"hi","models":["grok-3"]}}`)
+	if !strings.Contains(string(bad.Result), `"isError":true`) {
+		t.Errorf("compare_models with one model = %s", bad.Result)
+	}
+
+	if unknown := call(5, "resources/list", `{}`); unknown.Error == nil || unknown.Error.Code != rpcMethodNotFound {
+		t.Errorf("resources/list = %+v", unknown)
+	}
+	inW.Close()
+}
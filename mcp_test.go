@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/laurensent/ask/pkg/config"
+)
+
+// fakeMCPResult answers the requests the client sends.
+func fakeMCPResult(method string, params json.RawMessage) any {
+	switch method {
+	case "initialize":
+		return map[string]any{"protocolVersion": mcpProtocolVersion, "capabilities": map[string]any{"tools": map[string]any{}}, "serverInfo": map[string]any{"name": "fake"}}
+	case "tools/list":
+		return map[string]any{"tools": []any{map[string]any{
+			"name":        "add",
+			"description": "Add two numbers",
+			"inputSchema": map[string]any{"type": "object", "properties": map[string]any{"a": map[string]any{"type": "number"}, "b": map[string]any{"type": "number"}}, "required": []string{"a", "b"}},
+		}}}
+	case "tools/call":
+		var in struct {
+			Arguments struct{ A, B float64 }
+		}
+		json.Unmarshal(params, &in)
+		return map[string]any{"content": []any{map[string]any{"type": "text", "text": fmt.Sprint(in.Arguments.A + in.Arguments.B)}}}
+	}
+	return map[string]any{}
+}
+
+// TestMCPHelperProcess is not a real test: it is the stdio MCP server
+// that TestMCPStdio starts.
+func TestMCPHelperProcess(t *testing.T) {
+	if os.Getenv("ASK_MCP_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg rpcMessage
+		json.Unmarshal(scanner.Bytes(), &msg)
+		if msg.ID == nil || msg.Method == "test/hang" {
+			continue
+		}
+		result, _ := json.Marshal(fakeMCPResult(msg.Method, msg.Params))
+		data, _ := json.Marshal(rpcMessage{JSONRPC: "2.0", ID: msg.ID, Result: result})
+		fmt.Printf("%s\n", data)
+	}
+	os.Exit(0)
+}
+
+func TestMCPStdio(t *testing.T) {
+	sc := config.MCPServer{Command: os.Args[0], Args: []string{"-test.run=TestMCPHelperProcess"}, Env: map[string]string{"ASK_MCP_HELPER": "1"}}
+	s := connectMCPServers(context.Background(), map[string]config.MCPServer{"calc": sc})
+	defer s.close()
+	if len(s.tools) != 1 {
+		t.Fatalf("got %d tools, want 1", len(s.tools))
+	}
+	tool := s.tools[0]
+	if tool.Name() != "mcp__calc__add" {
+		t.Errorf("Name() = %q", tool.Name())
+	}
+	if req, _ := tool.Parameters()["required"].([]string); len(req) != 2 {
+		t.Errorf("required = %v, want [a b]", tool.Parameters()["required"])
+	}
+	out, err := tool.Run(context.Background(), json.RawMessage(`{"a":2,"b":3}`))
+	if err != nil || out != "5" {
+		t.Errorf("Run = %q, %v; want 5", out, err)
+	}
+}
+
+func TestMCPStdioCancel(t *testing.T) {
+	tr, err := newStdioTransport(config.MCPServer{Command: os.Args[0], Args: []string{"-test.run=TestMCPHelperProcess"}, Env: map[string]string{"ASK_MCP_HELPER": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := tr.roundTrip(ctx, rpcMessage{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: "test/hang"}); err != context.DeadlineExceeded {
+		t.Fatalf("roundTrip = %v, want deadline exceeded", err)
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if len(tr.pending) != 0 {
+		t.Errorf("%d requests still pending after cancel", len(tr.pending))
+	}
+}
+
+func TestMCPHTTP(t *testing.T) {
+	for _, sse := range []bool{false, true} {
+		var sessions []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var msg rpcMessage
+			json.NewDecoder(r.Body).Decode(&msg)
+			sessions = append(sessions, r.Header.Get("Mcp-Session-Id"))
+			w.Header().Set("Mcp-Session-Id", "s1")
+			if msg.ID == nil {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			result, _ := json.Marshal(fakeMCPResult(msg.Method, msg.Params))
+			data, _ := json.Marshal(rpcMessage{JSONRPC: "2.0", ID: msg.ID, Result: result})
+			if sse {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\ndata: %s\n\n", data)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(data)
+		}))
+
+		c, tools, err := connectAndList(context.Background(), "calc", config.MCPServer{URL: srv.URL})
+		if err != nil {
+			t.Fatalf("sse=%v: %v", sse, err)
+		}
+		text, _, err := c.callTool(context.Background(), tools[0].Name, json.RawMessage(`{"a":1,"b":1}`))
+		if err != nil || text != "2" {
+			t.Errorf("sse=%v: callTool = %q, %v", sse, text, err)
+		}
+		if sessions[0] != "" || sessions[len(sessions)-1] != "s1" {
+			t.Errorf("sse=%v: session headers = %q", sse, sessions)
+		}
+		srv.Close()
+	}
+}
+
+func TestMCPToolName(t *testing.T) {
+	tests := []struct{ server, tool, want string }{
+		{"github", "create_issue", "mcp__github__create_issue"},
+		{"my server", "get.file", "mcp__my_server__get_file"},
+		{"s", strings.Repeat("x", 80), "mcp__s__" + strings.Repeat("x", 56)},
+	}
+	for _, tt := range tests {
+		if got := mcpToolName(tt.server, tt.tool); got != tt.want {
+			t.Errorf("mcpToolName(%q, %q) = %q, want %q", tt.server, tt.tool, got, tt.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"text/tabwriter"
 
+	"github.com/laurensent/ask/pkg/ask"
 	"github.com/spf13/cobra"
 )
 
@@ -17,12 +18,12 @@ var modelsCmd = &cobra.Command{
 	Short: "List available models for the current provider",
 	Long:  "Show model aliases for the configured provider.\nWith --remote, query the provider API for all available models.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		providerName := cfg.resolvedProvider()
+		providerName := cfg.ResolvedProvider()
 		if cfg.Mode != "api" {
 			providerName = "anthropic"
 		}
 
-		p, err := getProvider(providerName)
+		p, err := ask.Get(providerName)
 		if err != nil {
 			return err
 		}
@@ -45,11 +46,8 @@ var modelsCmd = &cobra.Command{
 		w.Flush()
 
 		if remoteModels {
-			apiKey := os.Getenv(p.EnvKey())
-			if apiKey == "" {
-				apiKey = cfg.APIKey
-			}
-			if apiKey == "" && p.EnvKey() != "" {
+			apiKey := cfg.APIKeyFor(p)
+			if o, ok := p.(ask.OptionalKeyProvider); apiKey == "" && p.EnvKey() != "" && !(ok && o.APIKeyOptional()) {
 				return fmt.Errorf("--remote requires API key. Set %q or run: ask config", p.EnvKey())
 			}
 
@@ -64,7 +62,7 @@ var modelsCmd = &cobra.Command{
 				aliasIDs[p.ResolveModel(a)] = true
 			}
 
-			var extra []RemoteModel
+			var extra []ask.RemoteModel
 			for _, m := range models {
 				if !aliasIDs[m.ID] {
 					extra = append(extra, m)
@@ -90,6 +88,21 @@ var modelsCmd = &cobra.Command{
 	},
 }
 
+var modelsPullCmd = &cobra.Command{
+	Use:   "pull NAME",
+	Short: "Download an Ollama model",
+	Long:  "Pull a model into the local Ollama server, showing download progress.\nNAME may be an ollama alias (e.g. qwen) or any tag from ollama.com/library.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := ask.Get("ollama")
+		if err != nil {
+			return err
+		}
+		return pullOllamaModel(cmd.Context(), cfg.ForProvider("ollama").BaseURL, p.ResolveModel(args[0]))
+	},
+}
+
 func init() {
+	modelsCmd.AddCommand(modelsPullCmd)
 	modelsCmd.Flags().BoolVar(&remoteModels, "remote", false, "query provider API for all available models")
 }
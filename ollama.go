@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+// pullOllamaModel downloads name through Ollama, drawing progress on stderr.
+func pullOllamaModel(ctx context.Context, baseURL, name string) error {
+	bar := progress.New(progress.WithSolidFill("#E36C38"), progress.WithWidth(30))
+	tty := isStderrTerminal()
+	status, drawn := "", false
+	err := ask.PullOllamaModel(ctx, baseURL, name, func(ev ask.PullProgress) {
+		switch {
+		case tty && ev.Total > 0:
+			fmt.Fprintf(os.Stderr, "\r\033[K%s  %s  %d/%d MB", firstLine(ev.Status, 30),
+				bar.ViewAs(float64(ev.Completed)/float64(ev.Total)), ev.Completed>>20, ev.Total>>20)
+			drawn = true
+		case ev.Status != status:
+			if tty {
+				fmt.Fprint(os.Stderr, "\r\033[K")
+			}
+			fmt.Fprintln(os.Stderr, ev.Status)
+			drawn = false
+		}
+		status = ev.Status
+	})
+	if err != nil && drawn {
+		fmt.Fprintln(os.Stderr)
+	}
+	return err
+}
+
+// offerOllamaPull asks on the terminal whether to pull a model that Ollama
+// reported missing, and pulls it. It reports whether the model was pulled.
+func offerOllamaPull(err error, p ask.Provider, modelAlias, baseURL string) bool {
+	if p.Name() != "ollama" || !ask.OllamaMissingModel(err) {
+		return false
+	}
+	if modelAlias == "" {
+		modelAlias = p.DefaultModel()
+	}
+	name := p.ResolveModel(modelAlias)
+
+	tty, ttyErr := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if ttyErr != nil {
+		return false
+	}
+	defer tty.Close()
+	fmt.Fprintf(tty, "Model %s is not pulled. Pull it now? [Y/n] ", name)
+	answer, _ := bufio.NewReader(tty).ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "" && a != "y" && a != "yes" {
+		return false
+	}
+	if err := pullOllamaModel(context.Background(), baseURL, name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	return true
+}
@@ -6,8 +6,10 @@ import (
 	"os"
 	"strings"
 
-	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // isPiped returns true if stdin is not a terminal (i.e., data is being piped in).
@@ -28,6 +30,15 @@ func isStdoutTerminal() bool {
 	return (fi.Mode() & os.ModeCharDevice) != 0
 }
 
+// isStderrTerminal returns true if stderr is connected to a terminal.
+func isStderrTerminal() bool {
+	fi, err := os.Stderr.Stat()
+	if err != nil {
+		return false
+	}
+	return (fi.Mode() & os.ModeCharDevice) != 0
+}
+
 // readPipe reads all data from stdin and returns it as a string.
 func readPipe() (string, error) {
 	data, err := io.ReadAll(os.Stdin)
@@ -37,50 +48,100 @@ func readPipe() (string, error) {
 	return string(data), nil
 }
 
-// promptModel is a bubbletea model for interactive single-line input.
+// newPromptTextarea returns a multi-line textarea configured for prompt input.
+func newPromptTextarea() textarea.Model {
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.SetPromptFunc(2, func(line int) string {
+		if line == 0 {
+			return "> "
+		}
+		return "  "
+	})
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	// Ctrl+E opens the editor instead of jumping to line end.
+	ta.KeyMap.LineEnd = key.NewBinding(key.WithKeys("end"))
+	// Ctrl+D submits instead of deleting forward.
+	ta.KeyMap.DeleteCharacterForward = key.NewBinding(key.WithKeys("delete"))
+	ta.Focus()
+	return ta
+}
+
+// promptModel is a bubbletea model for interactive multi-line input.
 type promptModel struct {
-	input    textinput.Model
+	input    textarea.Model
 	result   string
 	canceled bool
+	err      error
 }
 
 func (m promptModel) Init() tea.Cmd {
-	return textinput.Blink
+	return textarea.Blink
 }
 
 func (m promptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.input.SetWidth(msg.Width)
+		return m, nil
+	case editorDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		} else {
+			m.input.SetValue(msg.text)
+		}
+		m.fitHeight()
+		return m, nil
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyEnter:
+		switch {
+		case msg.Type == tea.KeyCtrlD, msg.Type == tea.KeyEnter && msg.Alt:
 			m.result = m.input.Value()
 			return m, tea.Quit
-		case tea.KeyEsc, tea.KeyCtrlC:
+		case msg.Type == tea.KeyCtrlE:
+			return m, editTextCmd(m.input.Value())
+		case msg.Type == tea.KeyEsc, msg.Type == tea.KeyCtrlC:
 			m.canceled = true
 			return m, tea.Quit
 		}
 	}
 	var cmd tea.Cmd
 	m.input, cmd = m.input.Update(msg)
+	m.fitHeight()
 	return m, cmd
 }
 
+// fitHeight grows the textarea with its content, up to a limit.
+func (m *promptModel) fitHeight() {
+	m.input.SetHeight(min(max(m.input.LineCount(), 1), 10))
+}
+
 func (m promptModel) View() string {
-	return "> " + m.input.View()
+	if m.canceled {
+		return ""
+	}
+	if m.result != "" {
+		return m.input.View()
+	}
+	hint := "Ctrl+D / Alt+Enter = submit  Ctrl+E = editor  Esc = cancel"
+	if m.err != nil {
+		hint = m.err.Error()
+	}
+	return m.input.View() + "\n" + wizardDim.Render(hint)
 }
 
 // errCanceled is returned when the user cancels interactive input (ESC / Ctrl+C).
 var errCanceled = fmt.Errorf("canceled")
 
-// readInteractivePrompt reads a single line using bubbletea with full
-// emacs keybindings, ESC to cancel, and proper terminal handling.
+// readInteractivePrompt reads a multi-line prompt using bubbletea with
+// emacs keybindings, bracketed paste, Ctrl+E to edit in $EDITOR, ESC to
+// cancel, and proper terminal handling.
 // Returns (input, nil) on success, or ("", errCanceled) when canceled.
 func readInteractivePrompt() (string, error) {
-	ti := textinput.New()
-	ti.Focus()
-	ti.Prompt = ""
+	m := promptModel{input: newPromptTextarea()}
+	m.fitHeight()
 
-	p := tea.NewProgram(promptModel{input: ti}, tea.WithOutput(os.Stderr))
+	p := tea.NewProgram(m, tea.WithOutput(os.Stderr))
 	result, err := p.Run()
 	if err != nil {
 		return "", err
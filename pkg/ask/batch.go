@@ -0,0 +1,45 @@
+package ask
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const batchPollInterval = 30 * time.Second
+
+// BatchResponse is a single result from a provider batch API.
+type BatchResponse struct {
+	Text  string
+	Usage Usage
+	Err   error
+}
+
+// BatchProgress reports the state of a submitted batch.
+type BatchProgress struct {
+	ID          string // the provider's batch ID
+	Done, Total int
+}
+
+// BatchProvider is implemented by providers with an asynchronous batch API.
+// RunBatch submits reqs, polls until the batch ends, reporting progress
+// after each poll, and returns one response per request, in request order.
+// ResumeBatch waits for a batch that RunBatch already submitted, given the
+// same reqs in the same order.
+type BatchProvider interface {
+	RunBatch(ctx context.Context, reqs []Request, progress func(BatchProgress)) ([]BatchResponse, error)
+	ResumeBatch(ctx context.Context, id string, reqs []Request, progress func(BatchProgress)) ([]BatchResponse, error)
+}
+
+// batchCustomID and parseBatchCustomID map request indexes to the IDs sent
+// to batch APIs, which restrict the allowed characters.
+func batchCustomID(i int) string { return "req-" + strconv.Itoa(i) }
+
+func parseBatchCustomID(id string, n int) (int, bool) {
+	i, err := strconv.Atoi(strings.TrimPrefix(id, "req-"))
+	if err != nil || i < 0 || i >= n {
+		return 0, false
+	}
+	return i, true
+}
@@ -0,0 +1,76 @@
+package ask
+
+import (
+	"iter"
+	"strings"
+)
+
+// EventKind identifies what an Event carries.
+type EventKind int
+
+const (
+	EventText     EventKind = iota + 1 // Text: a piece of the answer
+	EventThinking                      // Text: a piece of the model's reasoning
+	EventToolCall                      // ToolCall: a complete call the model wants run
+	EventCitation                      // Source: the next footnote, in citation order
+	EventUsage                         // Usage: token counts for the whole response
+	EventStop                          // StopReason and ResponseID, once the response is complete
+	EventError                         // Err: the request failed; no events follow
+)
+
+// Event is one item of a streamed response. Only the fields named by
+// its Kind are set.
+type Event struct {
+	Kind       EventKind
+	Text       string
+	ToolCall   ToolCall
+	Source     Source
+	Usage      Usage
+	StopReason string // provider-specific, e.g. "end_turn", "stop", "tool_use"
+	ResponseID string // server-side response ID, for PreviousResponseID follow-ups
+	Err        error
+}
+
+// Stream is the sequence of events returned by Provider.Run. The request
+// is sent when the stream is iterated, so iterating again repeats it;
+// breaking out of the loop cancels it.
+type Stream = iter.Seq[Event]
+
+// errorStream returns a stream holding only err.
+func errorStream(err error) Stream {
+	return func(yield func(Event) bool) {
+		yield(Event{Kind: EventError, Err: err})
+	}
+}
+
+// Collect drains events into a Result, passing each piece of answer text
+// to emit if it is non-nil. It returns the error of an EventError.
+func Collect(events Stream, emit func(text string)) (Result, error) {
+	var res Result
+	var text, reasoning strings.Builder
+	for ev := range events {
+		switch ev.Kind {
+		case EventText:
+			text.WriteString(ev.Text)
+			if emit != nil {
+				emit(ev.Text)
+			}
+		case EventThinking:
+			reasoning.WriteString(ev.Text)
+		case EventToolCall:
+			res.ToolCalls = append(res.ToolCalls, ev.ToolCall)
+		case EventCitation:
+			res.Sources = append(res.Sources, ev.Source)
+		case EventUsage:
+			res.Usage = ev.Usage
+		case EventStop:
+			res.StopReason = ev.StopReason
+			res.ResponseID = ev.ResponseID
+		case EventError:
+			res.Text, res.Reasoning = text.String(), reasoning.String()
+			return res, ev.Err
+		}
+	}
+	res.Text, res.Reasoning = text.String(), reasoning.String()
+	return res, nil
+}
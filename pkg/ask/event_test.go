@@ -0,0 +1,78 @@
+package ask
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestChatCompletionsEvents(t *testing.T) {
+	chunks := []string{
+		`{"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"reasoning_content":"Need the time."}}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"content":"Checking."}}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"now","arguments":"{\"tz\":"}}]}}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"UTC\"}"}}]},"finish_reason":"tool_calls"}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":5,"total_tokens":17}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", c)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	req := UserRequest("what time is it")
+	req.APIKey = "key"
+	req.BaseURL = srv.URL
+	p := providers["xai"]
+
+	var kinds []EventKind
+	for ev := range p.Run(context.Background(), req) {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []EventKind{EventThinking, EventText, EventToolCall, EventUsage, EventStop}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("event kinds = %v, want %v", kinds, want)
+	}
+
+	res, err := Collect(p.Run(context.Background(), req), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Text != "Checking." || res.Reasoning != "Need the time." || res.StopReason != "tool_calls" {
+		t.Errorf("result = %+v", res)
+	}
+	if len(res.ToolCalls) != 1 || res.ToolCalls[0].Name != "now" || string(res.ToolCalls[0].Args) != `{"tz":"UTC"}` {
+		t.Errorf("tool calls = %+v", res.ToolCalls)
+	}
+	if res.Usage != (Usage{InputTokens: 12, OutputTokens: 5}) {
+		t.Errorf("usage = %+v", res.Usage)
+	}
+
+	// Breaking out of the loop stops the stream.
+	n := 0
+	for range p.Run(context.Background(), req) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("got %d events after break", n)
+	}
+}
+
+func TestCollectError(t *testing.T) {
+	events := func(yield func(Event) bool) {
+		_ = yield(Event{Kind: EventText, Text: "partial"}) &&
+			yield(Event{Kind: EventError, Err: fmt.Errorf("boom")}) &&
+			yield(Event{Kind: EventText, Text: " ignored"})
+	}
+	res, err := Collect(events, nil)
+	if err == nil || err.Error() != "boom" || res.Text != "partial" {
+		t.Errorf("Collect = %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package ask provides a common interface to LLM APIs. Providers register
+// themselves by name and stream responses to the caller without printing.
+package ask
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RemoteModel represents a model returned by a provider's API.
+type RemoteModel struct {
+	ID   string
+	Name string
+}
+
+// FeatureFlags controls optional provider features like thinking and web search.
+type FeatureFlags struct {
+	Thinking  bool
+	WebSearch bool
+}
+
+// Message is a single turn in a conversation.
+type Message struct {
+	Role    string `json:"role"` // "user" or "assistant"
+	Content string `json:"content"`
+
+	// Tool calls on assistant turns and their results on the following
+	// user turn, when the request has tools.
+	ToolCalls   []ToolCall   `json:"tool_calls,omitempty"`
+	ToolResults []ToolResult `json:"tool_results,omitempty"`
+
+	// CachePrefix is the length of the start of Content that repeats
+	// across requests, such as piped input, for providers that cache
+	// prompts.
+	CachePrefix int `json:"-"`
+}
+
+// PromptCache controls provider-side caching of long, repeated input.
+type PromptCache string
+
+const (
+	PromptCacheOff  PromptCache = ""     // no caching requested
+	PromptCacheAuto PromptCache = "auto" // cache large inputs for the provider's default time
+	PromptCache1h   PromptCache = "1h"   // cache large inputs for an hour
+)
+
+// ParsePromptCache parses an off, auto or 1h setting.
+func ParsePromptCache(s string) (PromptCache, error) {
+	switch s {
+	case "off", "":
+		return PromptCacheOff, nil
+	case "auto":
+		return PromptCacheAuto, nil
+	case "1h":
+		return PromptCache1h, nil
+	}
+	return "", fmt.Errorf("invalid prompt cache setting %q (use off, auto or 1h)", s)
+}
+
+// Request describes a single call to a provider.
+type Request struct {
+	Messages []Message
+	System   string
+	Model    string
+	APIKey   string
+	BaseURL  string
+	Features FeatureFlags
+	Cache    PromptCache // prompt caching, for providers that support it
+	Files    []string    // local files sent as inputs, for providers that accept them
+	Tools    []Tool      // local tools the model may call, for providers that accept them
+
+	// PreviousResponseID continues a conversation stored server-side
+	// (OpenAI Responses API). Providers without it ignore the field.
+	PreviousResponseID string
+}
+
+// Usage reports token counts for a completed request.
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64
+
+	// Prompt cache tokens, counted separately from InputTokens.
+	CacheReadTokens  int64
+	CacheWriteTokens int64
+}
+
+// Result is a response collected from its events by Collect.
+type Result struct {
+	Text       string // the answer, including citation markers
+	Usage      Usage
+	Reasoning  string     // reasoning text, for providers that return it
+	StopReason string     // why the model stopped, as reported by the provider
+	ResponseID string     // server-side response ID, for PreviousResponseID follow-ups
+	Sources    []Source   // web search citations, in footnote order
+	ToolCalls  []ToolCall // tools the model asked to run, when req.Tools is set
+}
+
+// UserRequest builds a single-turn request for prompt.
+func UserRequest(prompt string) Request {
+	return Request{Messages: []Message{{Role: "user", Content: prompt}}}
+}
+
+// Provider defines the interface for LLM API providers.
+// Run returns the response as a stream of events and does no printing
+// itself; Collect turns the stream into a Result.
+// Providers are registered by name with Register and looked up with Get.
+type Provider interface {
+	Name() string
+	ResolveModel(alias string) string
+	ModelAliases() []string
+	DefaultModel() string
+	EnvKey() string
+	Run(ctx context.Context, req Request) Stream
+	ListModels(ctx context.Context, apiKey, baseURL string) ([]RemoteModel, error)
+}
+
+// OptionalKeyProvider is implemented by providers that can also
+// authenticate without an API key, such as Azure with Entra ID.
+type OptionalKeyProvider interface {
+	APIKeyOptional() bool
+}
+
+// FileInputProvider is implemented by providers that can take local
+// files (PDFs, images) as inputs with some models.
+type FileInputProvider interface {
+	AcceptsFiles(model string) bool
+}
+
+// ToolCallingProvider is implemented by providers that support function
+// calling through Request.Tools.
+type ToolCallingProvider interface {
+	AcceptsTools() bool
+}
+
+// BaseURLProvider is implemented by providers with a default endpoint
+// that base_url overrides.
+type BaseURLProvider interface {
+	DefaultBaseURL() string
+}
+
+// providers is the registry of available providers.
+var providers = map[string]Provider{}
+
+// Register adds a provider to the registry.
+func Register(p Provider) {
+	providers[p.Name()] = p
+}
+
+// Lookup returns the provider with the given name, if registered.
+func Lookup(name string) (Provider, bool) {
+	p, ok := providers[name]
+	return p, ok
+}
+
+// Get returns the provider with the given name, or an error.
+func Get(name string) (Provider, error) {
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return p, nil
+}
+
+// Names returns a sorted list of registered provider names.
+func Names() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
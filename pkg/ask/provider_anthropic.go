@@ -0,0 +1,365 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+type anthropicProvider struct {
+	cloud CloudConfig // Bedrock or Vertex AI instead of the public API
+}
+
+func init() {
+	Register(anthropicProvider{})
+}
+
+func (anthropicProvider) Name() string { return "anthropic" }
+
+func (p anthropicProvider) ResolveModel(alias string) string {
+	aliases := map[string]string{
+		"opus":   "claude-opus-4-5-20251101",
+		"sonnet": "claude-sonnet-4-5-20250929",
+		"haiku":  "claude-haiku-4-5-20251001",
+	}
+	id, ok := aliases[alias]
+	if !ok {
+		id = alias
+	}
+	if p.cloud.Platform != "" {
+		return anthropicCloudModel(id, p.cloud)
+	}
+	return id
+}
+
+func (anthropicProvider) ModelAliases() []string {
+	return []string{"sonnet", "opus", "haiku"}
+}
+
+func (anthropicProvider) DefaultModel() string { return "sonnet" }
+
+func (anthropicProvider) EnvKey() string { return "ANTHROPIC_API_KEY" }
+
+// APIKeyOptional reports whether cloud credentials replace the API key.
+func (p anthropicProvider) APIKeyOptional() bool { return p.cloud.Platform != "" }
+
+// AcceptsTools reports that the provider supports function calling.
+func (anthropicProvider) AcceptsTools() bool { return true }
+
+// newClient returns a client for the public API, or for the configured cloud platform.
+func (p anthropicProvider) newClient(ctx context.Context, apiKey, baseURL string) (anthropic.Client, error) {
+	if p.cloud.Platform != "" {
+		opts, err := anthropicCloudOptions(ctx, p.cloud)
+		if err != nil {
+			return anthropic.Client{}, err
+		}
+		return anthropic.NewClient(opts...), nil
+	}
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	return anthropic.NewClient(opts...), nil
+}
+
+func (p anthropicProvider) ListModels(ctx context.Context, apiKey, baseURL string) ([]RemoteModel, error) {
+	if p.cloud.Platform != "" {
+		return nil, fmt.Errorf("listing models is not supported on %s", p.cloud.Platform)
+	}
+	client, err := p.newClient(ctx, apiKey, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []RemoteModel
+	iter := client.Models.ListAutoPaging(ctx, anthropic.ModelListParams{})
+	for iter.Next() {
+		m := iter.Current()
+		models = append(models, RemoteModel{ID: m.ID, Name: m.DisplayName})
+	}
+	if iter.Err() != nil {
+		return nil, fmt.Errorf("Anthropic API error: %v", iter.Err())
+	}
+	return models, nil
+}
+
+// messageParams converts req into Messages API parameters.
+func (p anthropicProvider) messageParams(req Request) anthropic.MessageNewParams {
+	modelID := p.ResolveModel(req.Model)
+	if modelID == "" {
+		modelID = p.ResolveModel(p.DefaultModel())
+	}
+
+	var messages []anthropic.MessageParam
+	for _, m := range req.Messages {
+		var blocks []anthropic.ContentBlockParamUnion
+		for _, r := range m.ToolResults {
+			blocks = append(blocks, anthropic.NewToolResultBlock(r.CallID, r.Content, r.IsError))
+		}
+		content := m.Content
+		if cc := cacheControl(req.Cache, m.CachePrefix); cc.Type != "" && m.CachePrefix <= len(content) {
+			// A breakpoint after the repeated prefix caches it however the
+			// rest of the message changes.
+			block := anthropic.TextBlockParam{Text: content[:m.CachePrefix], CacheControl: cc}
+			blocks = append(blocks, anthropic.ContentBlockParamUnion{OfText: &block})
+			content = content[m.CachePrefix:]
+		}
+		if content != "" || len(blocks) == 0 && len(m.ToolCalls) == 0 {
+			blocks = append(blocks, anthropic.NewTextBlock(content))
+		}
+		for _, c := range m.ToolCalls {
+			blocks = append(blocks, anthropic.NewToolUseBlock(c.ID, ToolArgs(c.Args), c.Name))
+		}
+		if m.Role == "assistant" {
+			messages = append(messages, anthropic.NewAssistantMessage(blocks...))
+		} else {
+			messages = append(messages, anthropic.NewUserMessage(blocks...))
+		}
+	}
+
+	params := anthropic.MessageNewParams{
+		Model:     anthropic.Model(modelID),
+		MaxTokens: 8192,
+		Messages:  messages,
+	}
+
+	if req.System != "" {
+		params.System = []anthropic.TextBlockParam{{Text: req.System, CacheControl: cacheControl(req.Cache, len(req.System))}}
+	}
+
+	// Thinking blocks would have to be replayed with every tool result, so
+	// the agent loop runs without them.
+	if req.Features.Thinking && len(req.Tools) == 0 {
+		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(10000)
+		params.MaxTokens = 16000
+	}
+
+	if req.Features.WebSearch {
+		params.Tools = append(params.Tools, anthropic.ToolUnionParam{
+			OfWebSearchTool20250305: &anthropic.WebSearchTool20250305Param{},
+		})
+	}
+	for _, t := range req.Tools {
+		schema := t.Parameters()
+		required, _ := schema["required"].([]string)
+		params.Tools = append(params.Tools, anthropic.ToolUnionParam{OfTool: &anthropic.ToolParam{
+			Name:        t.Name(),
+			Description: anthropic.String(t.Description()),
+			InputSchema: anthropic.ToolInputSchemaParam{Properties: schema["properties"], Required: required},
+		}})
+	}
+	return params
+}
+
+// promptCacheMinChars is the size from which input is worth caching,
+// about the 1024 tokens Anthropic requires for a cache breakpoint.
+const promptCacheMinChars = 4096
+
+// cacheControl returns the cache breakpoint for a block of size characters
+// under mode, or the zero value for none.
+func cacheControl(mode PromptCache, size int) anthropic.CacheControlEphemeralParam {
+	if mode == PromptCacheOff || size < promptCacheMinChars {
+		return anthropic.CacheControlEphemeralParam{}
+	}
+	c := anthropic.NewCacheControlEphemeralParam()
+	if mode == PromptCache1h {
+		c.TTL = anthropic.CacheControlEphemeralTTLTTL1h
+	}
+	return c
+}
+
+func (p anthropicProvider) Run(ctx context.Context, req Request) Stream {
+	return func(yield func(Event) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		client, err := p.newClient(ctx, req.APIKey, req.BaseURL)
+		if err != nil {
+			yield(Event{Kind: EventError, Err: err})
+			return
+		}
+		stream := client.Messages.NewStreaming(ctx, p.messageParams(req))
+
+		var usage Usage
+		var stop string
+		var sources, searched sourceList
+		var cited []int // footnote numbers cited by the current text block
+		var call *ToolCall
+		var toolInput strings.Builder
+		for stream.Next() {
+			var out []Event
+			switch ev := stream.Current().AsAny().(type) {
+			case anthropic.MessageStartEvent:
+				usage.InputTokens = ev.Message.Usage.InputTokens
+				usage.CacheReadTokens = ev.Message.Usage.CacheReadInputTokens
+				usage.CacheWriteTokens = ev.Message.Usage.CacheCreationInputTokens
+			case anthropic.MessageDeltaEvent:
+				usage.OutputTokens = ev.Usage.OutputTokens
+				stop = string(ev.Delta.StopReason)
+			case anthropic.ContentBlockStartEvent:
+				switch ev.ContentBlock.Type {
+				case "web_search_tool_result":
+					for _, r := range ev.ContentBlock.Content.OfWebSearchResultBlockArray {
+						searched.add(r.Title, r.URL)
+					}
+				case "tool_use":
+					call = &ToolCall{ID: ev.ContentBlock.ID, Name: ev.ContentBlock.Name}
+					toolInput.Reset()
+				}
+			case anthropic.ContentBlockDeltaEvent:
+				switch delta := ev.Delta.AsAny().(type) {
+				case anthropic.TextDelta:
+					out = append(out, Event{Kind: EventText, Text: delta.Text})
+				case anthropic.ThinkingDelta:
+					out = append(out, Event{Kind: EventThinking, Text: delta.Thinking})
+				case anthropic.InputJSONDelta:
+					toolInput.WriteString(delta.PartialJSON)
+				case anthropic.CitationsDelta:
+					n, isNew := sources.cite(delta.Citation.Title, delta.Citation.URL)
+					if isNew {
+						out = append(out, Event{Kind: EventCitation, Source: sources.sources[n-1]})
+					}
+					if n > 0 {
+						cited = append(cited, n)
+					}
+				}
+			case anthropic.ContentBlockStopEvent:
+				if call != nil {
+					call.Args = ToolArgs(json.RawMessage(toolInput.String()))
+					out = append(out, Event{Kind: EventToolCall, ToolCall: *call})
+					call = nil
+				} else if markers := citationMarkers(cited); markers != "" {
+					out = append(out, Event{Kind: EventText, Text: markers})
+				}
+				cited = nil
+			}
+			for _, ev := range out {
+				if !yield(ev) {
+					return
+				}
+			}
+		}
+		if stream.Err() != nil {
+			yield(Event{Kind: EventError, Err: fmt.Errorf("Anthropic API error: %v", stream.Err())})
+			return
+		}
+
+		// Without citations, fall back to everything the searches returned.
+		if len(sources.sources) == 0 {
+			for _, s := range searched.sources {
+				if !yield(Event{Kind: EventCitation, Source: s}) {
+					return
+				}
+			}
+		}
+		if yield(Event{Kind: EventUsage, Usage: usage}) {
+			yield(Event{Kind: EventStop, StopReason: stop})
+		}
+	}
+}
+
+// RunBatch submits reqs through the Message Batches API and waits for the results.
+func (p anthropicProvider) RunBatch(ctx context.Context, reqs []Request, progress func(BatchProgress)) ([]BatchResponse, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	if p.cloud.Platform != "" {
+		return nil, fmt.Errorf("the batch API is not available on %s", p.cloud.Platform)
+	}
+	client, err := p.newClient(ctx, reqs[0].APIKey, reqs[0].BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	params := anthropic.MessageBatchNewParams{}
+	for i, req := range reqs {
+		mp := p.messageParams(req)
+		params.Requests = append(params.Requests, anthropic.MessageBatchNewParamsRequest{
+			CustomID: batchCustomID(i),
+			Params: anthropic.MessageBatchNewParamsRequestParams{
+				Model:     mp.Model,
+				MaxTokens: mp.MaxTokens,
+				Messages:  mp.Messages,
+				System:    mp.System,
+				Thinking:  mp.Thinking,
+				Tools:     mp.Tools,
+			},
+		})
+	}
+
+	batch, err := client.Messages.Batches.New(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic API error: %v", err)
+	}
+	return p.waitBatch(ctx, client, batch, len(reqs), progress)
+}
+
+// ResumeBatch waits for the results of an already submitted message batch.
+func (p anthropicProvider) ResumeBatch(ctx context.Context, id string, reqs []Request, progress func(BatchProgress)) ([]BatchResponse, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	client, err := p.newClient(ctx, reqs[0].APIKey, reqs[0].BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := client.Messages.Batches.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic API error: %v", err)
+	}
+	return p.waitBatch(ctx, client, batch, len(reqs), progress)
+}
+
+// waitBatch polls batch until it ends and reads its n results.
+func (p anthropicProvider) waitBatch(ctx context.Context, client anthropic.Client, batch *anthropic.MessageBatch, n int, progress func(BatchProgress)) ([]BatchResponse, error) {
+	var err error
+	for batch.ProcessingStatus != anthropic.MessageBatchProcessingStatusEnded {
+		c := batch.RequestCounts
+		progress(BatchProgress{ID: batch.ID, Done: int(c.Succeeded + c.Errored + c.Canceled + c.Expired), Total: n})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(batchPollInterval):
+		}
+		if batch, err = client.Messages.Batches.Get(ctx, batch.ID); err != nil {
+			return nil, fmt.Errorf("Anthropic API error: %v", err)
+		}
+	}
+
+	out := make([]BatchResponse, n)
+	stream := client.Messages.Batches.ResultsStreaming(ctx, batch.ID)
+	for stream.Next() {
+		r := stream.Current()
+		i, ok := parseBatchCustomID(r.CustomID, n)
+		if !ok {
+			continue
+		}
+		if r.Result.Type != "succeeded" {
+			msg := r.Result.Type
+			if r.Result.Type == "errored" {
+				msg = r.Result.Error.Error.Message
+			}
+			out[i].Err = fmt.Errorf("batch request %s: %s", r.Result.Type, msg)
+			continue
+		}
+		var text strings.Builder
+		for _, block := range r.Result.Message.Content {
+			if block.Type == "text" {
+				text.WriteString(block.Text)
+			}
+		}
+		out[i].Text = text.String()
+		out[i].Usage = Usage{
+			InputTokens:  r.Result.Message.Usage.InputTokens,
+			OutputTokens: r.Result.Message.Usage.OutputTokens,
+		}
+	}
+	if stream.Err() != nil {
+		return nil, fmt.Errorf("Anthropic API error: %v", stream.Err())
+	}
+	return out, nil
+}
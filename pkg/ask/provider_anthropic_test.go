@@ -0,0 +1,84 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAnthropicPromptCache(t *testing.T) {
+	pipe := "Here is the input data:\n\n```\n" + strings.Repeat("log line\n", 600) + "```\n\n"
+	tests := []struct {
+		name       string
+		cache      PromptCache
+		system     string
+		prefix     int
+		wantBlocks int    // content blocks in the user message
+		wantCached string // JSON of the cache_control sent, or "" for none
+	}{
+		{"off", PromptCacheOff, "", len(pipe), 1, ""},
+		{"auto", PromptCacheAuto, "", len(pipe), 2, `{"type":"ephemeral"}`},
+		{"1h", PromptCache1h, "", len(pipe), 2, `{"ttl":"1h","type":"ephemeral"}`},
+		{"small prefix", PromptCacheAuto, "", 10, 1, ""},
+		{"system", PromptCacheAuto, strings.Repeat("Be terse. ", 500), 0, 1, `{"type":"ephemeral"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				System   []map[string]any `json:"system"`
+				Messages []struct {
+					Content []map[string]any `json:"content"`
+				} `json:"messages"`
+			}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(data, &body); err != nil {
+					t.Error(err)
+				}
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"model\":\"claude-sonnet-4-5\",\"usage\":{\"input_tokens\":12,\"cache_read_input_tokens\":1500,\"cache_creation_input_tokens\":0,\"output_tokens\":1}}}\n\n")
+				io.WriteString(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":5}}\n\n")
+				io.WriteString(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+			}))
+			defer srv.Close()
+
+			req := UserRequest(pipe + "What failed?")
+			req.Messages[0].CachePrefix = tt.prefix
+			req.System = tt.system
+			req.Cache = tt.cache
+			req.APIKey = "test-key"
+			req.BaseURL = srv.URL
+			res, err := Collect(providers["anthropic"].Run(context.Background(), req), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Usage.CacheReadTokens != 1500 || res.Usage.InputTokens != 12 {
+				t.Errorf("usage = %+v", res.Usage)
+			}
+
+			blocks := body.Messages[0].Content
+			if len(blocks) != tt.wantBlocks {
+				t.Fatalf("%d content blocks, want %d", len(blocks), tt.wantBlocks)
+			}
+			if tt.wantBlocks == 2 && blocks[1]["text"] != "What failed?" {
+				t.Errorf("question block = %q", blocks[1]["text"])
+			}
+			cached := blocks[0]
+			if tt.system != "" {
+				cached = body.System[0]
+			}
+			got := ""
+			if cc, ok := cached["cache_control"]; ok {
+				data, _ := json.Marshal(cc)
+				got = string(data)
+			}
+			if got != tt.wantCached {
+				t.Errorf("cache_control = %s, want %s", got, tt.wantCached)
+			}
+		})
+	}
+}
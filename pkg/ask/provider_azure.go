@@ -0,0 +1,132 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/openai/openai-go/v3/azure"
+	"github.com/openai/openai-go/v3/option"
+)
+
+const (
+	// azureDefaultAPIVersion is the GA data-plane version used for chat.
+	azureDefaultAPIVersion = "2024-10-21"
+	// azureDeploymentsAPIVersion is the last version with the deployments listing.
+	azureDeploymentsAPIVersion = "2022-12-01"
+	azureScope                 = "https://cognitiveservices.azure.com/.default"
+)
+
+func init() {
+	// Azure model names are deployment names chosen by the user, so there
+	// are no aliases; AZURE_OPENAI_DEPLOYMENT sets the default.
+	deployment := os.Getenv("AZURE_OPENAI_DEPLOYMENT")
+	if deployment == "" {
+		deployment = "gpt-4o"
+	}
+	Register(openaiCompatProvider{
+		name:       "azure",
+		envKey:     "AZURE_OPENAI_API_KEY",
+		defaultURL: os.Getenv("AZURE_OPENAI_ENDPOINT"),
+		aliases:    map[string]string{},
+		defaultMdl: deployment,
+		azure:      true,
+	})
+}
+
+// azureAPIVersion returns the api-version query parameter to send.
+func (p openaiCompatProvider) azureAPIVersion() string {
+	if v := os.Getenv("OPENAI_API_VERSION"); v != "" {
+		return v
+	}
+	if p.apiVersion != "" {
+		return p.apiVersion
+	}
+	return azureDefaultAPIVersion
+}
+
+// azureEndpoint returns the resource endpoint, e.g. https://NAME.openai.azure.com.
+func (p openaiCompatProvider) azureEndpoint(baseURL string) (string, error) {
+	if baseURL == "" {
+		baseURL = p.defaultURL
+	}
+	if baseURL == "" {
+		return "", fmt.Errorf("azure: set base_url or AZURE_OPENAI_ENDPOINT to https://<resource>.openai.azure.com")
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/openai"), nil
+}
+
+// azureOptions configures the OpenAI SDK for an Azure resource. Requests go
+// to the deployment named by the model field and authenticate with the
+// api-key header, or with an Entra ID token when no key is set.
+func (p openaiCompatProvider) azureOptions(apiKey, baseURL string) ([]option.RequestOption, error) {
+	endpoint, err := p.azureEndpoint(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	opts := []option.RequestOption{azure.WithEndpoint(endpoint, p.azureAPIVersion())}
+	if apiKey != "" {
+		return append(opts, azure.WithAPIKey(apiKey)), nil
+	}
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("azure: no API key and no Entra ID credential: %w", err)
+	}
+	return append(opts, azure.WithTokenCredential(cred)), nil
+}
+
+// listAzureDeployments lists the resource's deployments for `ask models --remote`.
+func (p openaiCompatProvider) listAzureDeployments(ctx context.Context, apiKey, baseURL string) ([]RemoteModel, error) {
+	endpoint, err := p.azureEndpoint(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		endpoint+"/openai/deployments?api-version="+azureDeploymentsAPIVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		req.Header.Set("api-key", apiKey)
+	} else {
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("azure: no API key and no Entra ID credential: %w", err)
+		}
+		tok, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureScope}})
+		if err != nil {
+			return nil, fmt.Errorf("azure: failed to get Entra ID token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+tok.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Azure at %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("azure API error: %s", resp.Status)
+	}
+
+	var result struct {
+		Data []struct {
+			ID    string `json:"id"`
+			Model string `json:"model"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse Azure response: %w", err)
+	}
+
+	var models []RemoteModel
+	for _, d := range result.Data {
+		models = append(models, RemoteModel{ID: d.ID, Name: d.Model})
+	}
+	return models, nil
+}
@@ -0,0 +1,66 @@
+package ask
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAzureEndpoint(t *testing.T) {
+	p := providers["azure"].(openaiCompatProvider)
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"https://res.openai.azure.com", "https://res.openai.azure.com"},
+		{"https://res.openai.azure.com/", "https://res.openai.azure.com"},
+		{"https://res.openai.azure.com/openai", "https://res.openai.azure.com"},
+	}
+	for _, tt := range tests {
+		got, err := p.azureEndpoint(tt.baseURL)
+		if err != nil || got != tt.want {
+			t.Errorf("azureEndpoint(%q) = %q, %v; want %q", tt.baseURL, got, err, tt.want)
+		}
+	}
+
+	p.defaultURL = ""
+	if _, err := p.azureEndpoint(""); err == nil {
+		t.Error("expected error without endpoint")
+	}
+}
+
+func TestAzureRun(t *testing.T) {
+	var gotPath, gotVersion, gotKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotVersion, gotKey = r.URL.Path, r.URL.Query().Get("api-version"), r.Header.Get("Api-Key")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"gpt-4o","choices":[{"index":0,"delta":{"content":"hi"}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	p := providers["azure"].(openaiCompatProvider)
+	p.apiVersion = "2025-01-01-preview"
+	t.Setenv("OPENAI_API_VERSION", "")
+
+	req := UserRequest("hello")
+	req.Model = "team-gpt4o"
+	req.APIKey = "secret"
+	req.BaseURL = srv.URL
+
+	var text string
+	if _, err := Collect(p.Run(context.Background(), req), func(s string) { text += s }); err != nil {
+		t.Fatal(err)
+	}
+	if text != "hi" {
+		t.Errorf("text = %q", text)
+	}
+	if gotPath != "/openai/deployments/team-gpt4o/chat/completions" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotVersion != "2025-01-01-preview" || gotKey != "secret" {
+		t.Errorf("api-version = %q, api-key = %q", gotVersion, gotKey)
+	}
+}
@@ -0,0 +1,132 @@
+package ask
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go/bedrock"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/vertex"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"golang.org/x/oauth2/google"
+)
+
+// CloudConfig routes a provider through a cloud platform instead of its
+// public API, using the platform's own credentials rather than an API key.
+type CloudConfig struct {
+	Platform string `json:"platform"`          // "bedrock" (anthropic) or "vertex" (anthropic, gemini)
+	Region   string `json:"region,omitempty"`  // AWS region or Vertex location
+	Profile  string `json:"profile,omitempty"` // AWS shared config profile
+	Project  string `json:"project,omitempty"` // Google Cloud project
+}
+
+// registerCloudProviders re-registers anthropic and gemini with the platforms
+// configured in the cloud section.
+func registerCloudProviders(cloud map[string]CloudConfig) error {
+	for name, cc := range cloud {
+		switch {
+		case name == "anthropic" && (cc.Platform == "bedrock" || cc.Platform == "vertex"):
+			Register(anthropicProvider{cloud: cc})
+		case name == "gemini" && cc.Platform == "vertex":
+			Register(geminiProvider{cloud: cc})
+		default:
+			return fmt.Errorf("cloud: unsupported platform %q for %s (anthropic: bedrock, vertex; gemini: vertex)", cc.Platform, name)
+		}
+	}
+	return nil
+}
+
+// vertexLocation returns the Vertex AI location, defaulting to the global endpoint.
+func (c CloudConfig) vertexLocation() string {
+	for _, v := range []string{c.Region, os.Getenv("GOOGLE_CLOUD_LOCATION")} {
+		if v != "" {
+			return v
+		}
+	}
+	return "global"
+}
+
+// vertexProject returns the Google Cloud project from config or environment.
+func (c CloudConfig) vertexProject() string {
+	if c.Project != "" {
+		return c.Project
+	}
+	return os.Getenv("GOOGLE_CLOUD_PROJECT")
+}
+
+// anthropicCloudOptions returns SDK options that send Messages API calls to
+// Bedrock (SigV4 from the AWS credential chain) or Vertex AI (Application
+// Default Credentials).
+func anthropicCloudOptions(ctx context.Context, c CloudConfig) ([]option.RequestOption, error) {
+	switch c.Platform {
+	case "bedrock":
+		var fns []func(*awsconfig.LoadOptions) error
+		if c.Region != "" {
+			fns = append(fns, awsconfig.WithRegion(c.Region))
+		}
+		if c.Profile != "" {
+			fns = append(fns, awsconfig.WithSharedConfigProfile(c.Profile))
+		}
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx, fns...)
+		if err != nil {
+			return nil, fmt.Errorf("bedrock: failed to load AWS config: %w", err)
+		}
+		if awsCfg.Region == "" {
+			return nil, fmt.Errorf("bedrock: set cloud.anthropic.region or AWS_REGION")
+		}
+		return []option.RequestOption{bedrock.WithConfig(awsCfg)}, nil
+	case "vertex":
+		creds, err := google.FindDefaultCredentials(ctx, "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			return nil, fmt.Errorf("vertex: no Application Default Credentials (run: gcloud auth application-default login): %w", err)
+		}
+		project := c.vertexProject()
+		if project == "" {
+			project = creds.ProjectID
+		}
+		return []option.RequestOption{vertex.WithCredentials(ctx, c.vertexLocation(), project, creds)}, nil
+	}
+	return nil, fmt.Errorf("unknown platform %q", c.Platform)
+}
+
+// datedModel matches Anthropic model IDs with a release date suffix.
+var datedModel = regexp.MustCompile(`^(claude-.+)-(\d{8})$`)
+
+// anthropicCloudModel maps a public Anthropic model ID to its platform ID:
+// a geo inference profile on Bedrock (us.anthropic.claude-...-v1:0) or the
+// @date form on Vertex (claude-sonnet-4-5@20250929). IDs already in
+// platform form are returned unchanged.
+func anthropicCloudModel(id string, c CloudConfig) string {
+	if !strings.HasPrefix(id, "claude-") || strings.Contains(id, "@") {
+		return id
+	}
+	switch c.Platform {
+	case "bedrock":
+		return bedrockGeo(c.Region) + "anthropic." + id + "-v1:0"
+	case "vertex":
+		if m := datedModel.FindStringSubmatch(id); m != nil {
+			return m[1] + "@" + m[2]
+		}
+	}
+	return id
+}
+
+// bedrockGeo returns the cross-region inference profile prefix for region.
+func bedrockGeo(region string) string {
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region == "" {
+			region = os.Getenv(env)
+		}
+	}
+	switch {
+	case strings.HasPrefix(region, "eu-"):
+		return "eu."
+	case strings.HasPrefix(region, "ap-"):
+		return "apac."
+	default:
+		return "us."
+	}
+}
@@ -0,0 +1,47 @@
+package ask
+
+import "testing"
+
+func TestAnthropicCloudModel(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	tests := []struct {
+		alias string
+		cloud CloudConfig
+		want  string
+	}{
+		{"sonnet", CloudConfig{}, "claude-sonnet-4-5-20250929"},
+		{"sonnet", CloudConfig{Platform: "bedrock", Region: "us-west-2"}, "us.anthropic.claude-sonnet-4-5-20250929-v1:0"},
+		{"opus", CloudConfig{Platform: "bedrock", Region: "eu-central-1"}, "eu.anthropic.claude-opus-4-5-20251101-v1:0"},
+		{"haiku", CloudConfig{Platform: "bedrock", Region: "ap-northeast-1"}, "apac.anthropic.claude-haiku-4-5-20251001-v1:0"},
+		{"global.anthropic.claude-sonnet-4-5-20250929-v1:0", CloudConfig{Platform: "bedrock"}, "global.anthropic.claude-sonnet-4-5-20250929-v1:0"},
+		{"sonnet", CloudConfig{Platform: "vertex"}, "claude-sonnet-4-5@20250929"},
+		{"claude-opus-4-1@20250805", CloudConfig{Platform: "vertex"}, "claude-opus-4-1@20250805"},
+	}
+	for _, tt := range tests {
+		p := anthropicProvider{cloud: tt.cloud}
+		if got := p.ResolveModel(tt.alias); got != tt.want {
+			t.Errorf("ResolveModel(%q) on %q = %q, want %q", tt.alias, tt.cloud.Platform, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterCloudProviders(t *testing.T) {
+	defer Register(anthropicProvider{})
+	defer Register(geminiProvider{})
+
+	err := registerCloudProviders(map[string]CloudConfig{
+		"anthropic": {Platform: "bedrock", Region: "us-east-1"},
+		"gemini":    {Platform: "vertex", Project: "p"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := providers["anthropic"].(anthropicProvider); p.cloud.Platform != "bedrock" || !p.APIKeyOptional() {
+		t.Errorf("anthropic not routed through bedrock: %+v", p.cloud)
+	}
+
+	if err := registerCloudProviders(map[string]CloudConfig{"gemini": {Platform: "bedrock"}}); err == nil {
+		t.Error("expected error for gemini on bedrock")
+	}
+}
@@ -0,0 +1,105 @@
+package ask
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ProviderConfig declares an OpenAI-compatible endpoint in the providers
+// config section, so several gateways can be used side by side.
+type ProviderConfig struct {
+	Name         string            `json:"name"`
+	BaseURL      string            `json:"base_url"`
+	EnvKey       string            `json:"env_key,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Aliases      map[string]string `json:"aliases,omitempty"`
+	DefaultModel string            `json:"default_model,omitempty"`
+}
+
+// newConfigProvider builds a provider from a providers config entry.
+// Header values may reference environment variables as $VAR or ${VAR}.
+func newConfigProvider(pc ProviderConfig) (openaiCompatProvider, error) {
+	if pc.Name == "" {
+		return openaiCompatProvider{}, fmt.Errorf("providers: entry without a name")
+	}
+	if pc.BaseURL == "" {
+		return openaiCompatProvider{}, fmt.Errorf("providers: %s: base_url is required", pc.Name)
+	}
+
+	aliasList := make([]string, 0, len(pc.Aliases))
+	for alias := range pc.Aliases {
+		aliasList = append(aliasList, alias)
+	}
+	sort.Strings(aliasList)
+
+	defaultMdl := pc.DefaultModel
+	if defaultMdl == "" && len(aliasList) > 0 {
+		defaultMdl = aliasList[0]
+	}
+	if defaultMdl == "" {
+		return openaiCompatProvider{}, fmt.Errorf("providers: %s: set default_model or aliases", pc.Name)
+	}
+
+	headers := make(map[string]string, len(pc.Headers))
+	for k, v := range pc.Headers {
+		headers[k] = os.ExpandEnv(v)
+	}
+
+	return openaiCompatProvider{
+		name:       pc.Name,
+		envKey:     pc.EnvKey,
+		defaultURL: pc.BaseURL,
+		headers:    headers,
+		aliases:    pc.Aliases,
+		aliasList:  aliasList,
+		defaultMdl: defaultMdl,
+	}, nil
+}
+
+// Options configures the registered providers beyond their built-in
+// defaults.
+type Options struct {
+	Providers       []ProviderConfig          // extra OpenAI-compatible providers
+	ProviderOptions map[string]map[string]any // extra request body fields per OpenAI-compatible provider
+	AzureAPIVersion string
+	Ollama          OllamaOptions
+	Cloud           map[string]CloudConfig // anthropic on Bedrock or Vertex AI, gemini on Vertex AI
+	MockScript      string                 // replies for the mock provider; $ASK_MOCK_SCRIPT overrides
+}
+
+// Configure registers the providers declared in o. An entry with the name
+// of a built-in provider replaces it. Provider options, the Azure API
+// version, Ollama options and cloud platforms are then applied to the
+// providers they belong to. The mock provider is registered when a script
+// is set.
+func Configure(o Options) error {
+	for _, pc := range o.Providers {
+		p, err := newConfigProvider(pc)
+		if err != nil {
+			return err
+		}
+		Register(p)
+	}
+	for name, opts := range o.ProviderOptions {
+		p, ok := providers[name].(openaiCompatProvider)
+		if !ok {
+			return fmt.Errorf("provider_options: %q is not an OpenAI-compatible provider", name)
+		}
+		p.extraBody = opts
+		Register(p)
+	}
+	if p, ok := providers["azure"].(openaiCompatProvider); ok && o.AzureAPIVersion != "" {
+		p.apiVersion = o.AzureAPIVersion
+		Register(p)
+	}
+	if p, ok := providers["ollama"].(ollamaProvider); ok {
+		p.options = o.Ollama
+		Register(p)
+	}
+	if script := cmp.Or(os.Getenv("ASK_MOCK_SCRIPT"), o.MockScript); script != "" {
+		Register(mockProvider{script: script, state: &mockState{}})
+	}
+	return registerCloudProviders(o.Cloud)
+}
@@ -0,0 +1,52 @@
+package ask
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewConfigProvider(t *testing.T) {
+	t.Setenv("GATEWAY_TEAM", "search")
+
+	p, err := newConfigProvider(ProviderConfig{
+		Name:    "vllm",
+		BaseURL: "http://gateway.internal/v1",
+		EnvKey:  "VLLM_API_KEY",
+		Headers: map[string]string{"X-Team": "${GATEWAY_TEAM}"},
+		Aliases: map[string]string{"qwen": "Qwen/Qwen3-32B", "llama": "meta-llama/Llama-3.3-70B-Instruct"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != "vllm" || p.EnvKey() != "VLLM_API_KEY" {
+		t.Errorf("name/env = %q/%q", p.Name(), p.EnvKey())
+	}
+	if want := []string{"llama", "qwen"}; !reflect.DeepEqual(p.ModelAliases(), want) {
+		t.Errorf("ModelAliases() = %v, want %v", p.ModelAliases(), want)
+	}
+	if p.DefaultModel() != "llama" {
+		t.Errorf("DefaultModel() = %q, want first alias", p.DefaultModel())
+	}
+	if got := p.ResolveModel("qwen"); got != "Qwen/Qwen3-32B" {
+		t.Errorf("ResolveModel(qwen) = %q", got)
+	}
+	if got := p.headers["X-Team"]; got != "search" {
+		t.Errorf("header X-Team = %q, want env expanded", got)
+	}
+}
+
+func TestNewConfigProviderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		pc   ProviderConfig
+	}{
+		{"no name", ProviderConfig{BaseURL: "http://x/v1", DefaultModel: "m"}},
+		{"no base url", ProviderConfig{Name: "x", DefaultModel: "m"}},
+		{"no model", ProviderConfig{Name: "x", BaseURL: "http://x/v1"}},
+	}
+	for _, tt := range tests {
+		if _, err := newConfigProvider(tt.pc); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+type geminiProvider struct {
+	cloud CloudConfig // Vertex AI instead of the Gemini API
+}
+
+func init() {
+	Register(geminiProvider{})
+}
+
+func (geminiProvider) Name() string { return "gemini" }
+
+func (geminiProvider) ResolveModel(alias string) string {
+	aliases := map[string]string{
+		"flash":      "gemini-2.5-flash",
+		"pro":        "gemini-2.5-pro",
+		"flash-lite": "gemini-2.0-flash-lite",
+	}
+	if id, ok := aliases[alias]; ok {
+		return id
+	}
+	return alias
+}
+
+func (geminiProvider) ModelAliases() []string {
+	return []string{"flash", "pro", "flash-lite"}
+}
+
+func (geminiProvider) DefaultModel() string { return "flash" }
+
+func (geminiProvider) EnvKey() string { return "GEMINI_API_KEY" }
+
+// APIKeyOptional reports whether Vertex AI credentials replace the API key.
+func (p geminiProvider) APIKeyOptional() bool { return p.cloud.Platform != "" }
+
+// AcceptsTools reports that the provider supports function calling.
+func (geminiProvider) AcceptsTools() bool { return true }
+
+// newClient returns a genai client for the Gemini API, or for Vertex AI
+// with Application Default Credentials. baseURL overrides the endpoint.
+func (p geminiProvider) newClient(ctx context.Context, apiKey, baseURL string) (*genai.Client, error) {
+	cc := &genai.ClientConfig{
+		APIKey:      apiKey,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: baseURL},
+	}
+	if p.cloud.Platform == "vertex" {
+		cc.APIKey = ""
+		cc.Backend = genai.BackendVertexAI
+		cc.Project = p.cloud.vertexProject()
+		cc.Location = p.cloud.vertexLocation()
+	}
+	client, err := genai.NewClient(ctx, cc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	return client, nil
+}
+
+func (p geminiProvider) ListModels(ctx context.Context, apiKey, baseURL string) ([]RemoteModel, error) {
+	client, err := p.newClient(ctx, apiKey, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := client.Models.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Gemini API error: %v", err)
+	}
+
+	var models []RemoteModel
+	for _, m := range page.Items {
+		id := strings.TrimPrefix(m.Name, "models/")
+		// Only include generateContent-capable models
+		supported := false
+		for _, action := range m.SupportedActions {
+			if action == "generateContent" {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			continue
+		}
+		models = append(models, RemoteModel{ID: id, Name: m.DisplayName})
+	}
+	return models, nil
+}
+
+func (p geminiProvider) Run(ctx context.Context, req Request) Stream {
+	modelID := p.ResolveModel(req.Model)
+	if modelID == "" {
+		modelID = p.ResolveModel(p.DefaultModel())
+	}
+
+	return func(yield func(Event) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		client, err := p.newClient(ctx, req.APIKey, req.BaseURL)
+		if err != nil {
+			yield(Event{Kind: EventError, Err: err})
+			return
+		}
+
+		var contents []*genai.Content
+		for _, m := range req.Messages {
+			role := genai.Role(genai.RoleUser)
+			if m.Role == "assistant" {
+				role = genai.RoleModel
+			}
+			contents = append(contents, geminiContent(m, role))
+		}
+
+		var config *genai.GenerateContentConfig
+		if req.Features.Thinking || req.Features.WebSearch || req.System != "" || len(req.Tools) > 0 {
+			config = &genai.GenerateContentConfig{}
+			if req.System != "" {
+				config.SystemInstruction = genai.NewContentFromText(req.System, genai.RoleUser)
+			}
+			if req.Features.Thinking {
+				config.ThinkingConfig = &genai.ThinkingConfig{
+					IncludeThoughts: true,
+					ThinkingBudget:  genai.Ptr(int32(10000)),
+				}
+			}
+			if req.Features.WebSearch {
+				config.Tools = []*genai.Tool{
+					{GoogleSearch: &genai.GoogleSearch{}},
+				}
+			}
+			if len(req.Tools) > 0 {
+				tool := &genai.Tool{}
+				for _, t := range req.Tools {
+					tool.FunctionDeclarations = append(tool.FunctionDeclarations, &genai.FunctionDeclaration{
+						Name:                 t.Name(),
+						Description:          t.Description(),
+						ParametersJsonSchema: t.Parameters(),
+					})
+				}
+				config.Tools = append(config.Tools, tool)
+			}
+		}
+
+		var usage Usage
+		var stop string
+		var sources sourceList
+		for result, err := range client.Models.GenerateContentStream(ctx, modelID, contents, config) {
+			if err != nil {
+				yield(Event{Kind: EventError, Err: fmt.Errorf("Gemini API error: %v", err)})
+				return
+			}
+			if u := result.UsageMetadata; u != nil {
+				usage.InputTokens = int64(u.PromptTokenCount)
+				usage.OutputTokens = int64(u.CandidatesTokenCount + u.ThoughtsTokenCount)
+			}
+			var out []Event
+			for _, c := range result.Candidates {
+				if c.FinishReason != "" {
+					stop = string(c.FinishReason)
+				}
+				if c.GroundingMetadata == nil {
+					continue
+				}
+				for _, chunk := range c.GroundingMetadata.GroundingChunks {
+					if chunk.Web == nil {
+						continue
+					}
+					if n, isNew := sources.cite(chunk.Web.Title, chunk.Web.URI); isNew {
+						out = append(out, Event{Kind: EventCitation, Source: sources.sources[n-1]})
+					}
+				}
+			}
+			if len(result.Candidates) > 0 && result.Candidates[0].Content != nil {
+				for _, part := range result.Candidates[0].Content.Parts {
+					switch {
+					case part.FunctionCall != nil:
+						args, _ := json.Marshal(part.FunctionCall.Args)
+						// The Gemini API leaves ID empty; results then match by name.
+						out = append(out, Event{Kind: EventToolCall, ToolCall: ToolCall{
+							ID:        part.FunctionCall.ID,
+							Name:      part.FunctionCall.Name,
+							Args:      args,
+							Signature: part.ThoughtSignature,
+						}})
+					case part.Text != "" && part.Thought:
+						out = append(out, Event{Kind: EventThinking, Text: part.Text})
+					case part.Text != "":
+						out = append(out, Event{Kind: EventText, Text: part.Text})
+					}
+				}
+			}
+			for _, ev := range out {
+				if !yield(ev) {
+					return
+				}
+			}
+		}
+		if yield(Event{Kind: EventUsage, Usage: usage}) {
+			yield(Event{Kind: EventStop, StopReason: stop})
+		}
+	}
+}
+
+// geminiContent converts a message, including agent tool calls and results.
+func geminiContent(m Message, role genai.Role) *genai.Content {
+	var parts []*genai.Part
+	for _, r := range m.ToolResults {
+		key := "output"
+		if r.IsError {
+			key = "error"
+		}
+		part := genai.NewPartFromFunctionResponse(r.Name, map[string]any{key: r.Content})
+		part.FunctionResponse.ID = r.CallID
+		parts = append(parts, part)
+	}
+	if m.Content != "" || len(m.ToolCalls)+len(m.ToolResults) == 0 {
+		parts = append(parts, genai.NewPartFromText(m.Content))
+	}
+	for _, c := range m.ToolCalls {
+		var args map[string]any
+		json.Unmarshal(c.Args, &args)
+		part := genai.NewPartFromFunctionCall(c.Name, args)
+		part.FunctionCall.ID = c.ID
+		part.ThoughtSignature = c.Signature
+		parts = append(parts, part)
+	}
+	return genai.NewContentFromParts(parts, role)
+}
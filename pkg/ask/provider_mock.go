@@ -0,0 +1,180 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// MockResponse is one scripted reply of the mock provider.
+type MockResponse struct {
+	// Match selects the reply for prompts containing it. Replies without
+	// Match are used in order by prompts that match nothing, and the last
+	// of them repeats.
+	Match string `json:"match,omitempty"`
+
+	Text       string         `json:"text,omitempty"`
+	Thinking   string         `json:"thinking,omitempty"`
+	ToolCalls  []MockToolCall `json:"tool_calls,omitempty"`
+	Sources    []MockSource   `json:"sources,omitempty"`
+	Usage      *MockUsage     `json:"usage,omitempty"` // default: word counts
+	StopReason string         `json:"stop_reason,omitempty"`
+	Error      string         `json:"error,omitempty"` // fail with this message after the text
+}
+
+// MockToolCall is a scripted tool call.
+type MockToolCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// MockSource is a scripted citation.
+type MockSource struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+// MockUsage is a scripted token count.
+type MockUsage struct {
+	InputTokens      int64 `json:"input_tokens"`
+	OutputTokens     int64 `json:"output_tokens"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+}
+
+// mockProvider replays the replies of a script file, for testing without
+// network access. It is registered as "mock" when a script is configured.
+type mockProvider struct {
+	script string
+	state  *mockState
+}
+
+type mockState struct {
+	mu   sync.Mutex
+	next int // index into the replies without Match
+}
+
+func (mockProvider) Name() string                     { return "mock" }
+func (mockProvider) EnvKey() string                   { return "" }
+func (mockProvider) DefaultModel() string             { return "mock" }
+func (mockProvider) ModelAliases() []string           { return []string{"mock"} }
+func (mockProvider) ResolveModel(alias string) string { return alias }
+
+// AcceptsTools reports that the provider supports function calling.
+func (mockProvider) AcceptsTools() bool { return true }
+
+func (mockProvider) ListModels(context.Context, string, string) ([]RemoteModel, error) {
+	return []RemoteModel{{ID: "mock", Name: "Scripted responses"}}, nil
+}
+
+// LoadMockScript reads a JSON array of replies.
+func LoadMockScript(path string) ([]MockResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("mock script: %w", err)
+	}
+	var replies []MockResponse
+	if err := json.Unmarshal(data, &replies); err != nil {
+		return nil, fmt.Errorf("mock script %s: %w", path, err)
+	}
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("mock script %s has no replies", path)
+	}
+	return replies, nil
+}
+
+// reply picks the scripted reply for req.
+func (p mockProvider) reply(req Request) (MockResponse, error) {
+	replies, err := LoadMockScript(p.script)
+	if err != nil {
+		return MockResponse{}, err
+	}
+	prompt := ""
+	if n := len(req.Messages); n > 0 {
+		prompt = req.Messages[n-1].Content
+	}
+	var ordered []MockResponse
+	for _, r := range replies {
+		if r.Match == "" {
+			ordered = append(ordered, r)
+		} else if strings.Contains(prompt, r.Match) {
+			return r, nil
+		}
+	}
+	if len(ordered) == 0 {
+		return MockResponse{}, fmt.Errorf("mock script %s has no reply for %q", p.script, prompt)
+	}
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	r := ordered[min(p.state.next, len(ordered)-1)]
+	p.state.next++
+	return r, nil
+}
+
+func (p mockProvider) Run(ctx context.Context, req Request) Stream {
+	return func(yield func(Event) bool) {
+		r, err := p.reply(req)
+		if err != nil {
+			yield(Event{Kind: EventError, Err: err})
+			return
+		}
+
+		var events []Event
+		if r.Thinking != "" {
+			events = append(events, Event{Kind: EventThinking, Text: r.Thinking})
+		}
+		// Stream the text a word at a time, like a real provider.
+		for _, word := range strings.SplitAfter(r.Text, " ") {
+			if word != "" {
+				events = append(events, Event{Kind: EventText, Text: word})
+			}
+		}
+		for _, s := range r.Sources {
+			events = append(events, Event{Kind: EventCitation, Source: Source(s)})
+		}
+		for i, c := range r.ToolCalls {
+			call := ToolCall{ID: fmt.Sprintf("call_%d", i+1), Name: c.Name, Args: ToolArgs(c.Args)}
+			events = append(events, Event{Kind: EventToolCall, ToolCall: call})
+		}
+		if r.Error != "" {
+			events = append(events, Event{Kind: EventError, Err: errors.New(r.Error)})
+		} else {
+			usage := Usage{InputTokens: int64(len(strings.Fields(req.System + " " + promptText(req)))), OutputTokens: int64(len(strings.Fields(r.Text)))}
+			if r.Usage != nil {
+				usage = Usage(*r.Usage)
+			}
+			stop := r.StopReason
+			if stop == "" {
+				stop = "end_turn"
+				if len(r.ToolCalls) > 0 {
+					stop = "tool_use"
+				}
+			}
+			events = append(events, Event{Kind: EventUsage, Usage: usage}, Event{Kind: EventStop, StopReason: stop})
+		}
+
+		for _, ev := range events {
+			if ctx.Err() != nil {
+				yield(Event{Kind: EventError, Err: ctx.Err()})
+				return
+			}
+			if !yield(ev) {
+				return
+			}
+		}
+	}
+}
+
+// promptText joins the content of req's messages.
+func promptText(req Request) string {
+	var b strings.Builder
+	for _, m := range req.Messages {
+		b.WriteString(m.Content)
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
@@ -0,0 +1,280 @@
+package ask
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// OllamaOptions are the ollama config settings sent with every chat request.
+type OllamaOptions struct {
+	NumCtx    int            `json:"num_ctx,omitempty"`
+	KeepAlive string         `json:"keep_alive,omitempty"` // e.g. "10m", "-1" to keep loaded
+	Options   map[string]any `json:"options,omitempty"`    // temperature, num_predict, top_p, ...
+}
+
+// ollamaProvider implements Provider with Ollama's native /api/chat.
+type ollamaProvider struct {
+	options OllamaOptions
+}
+
+func init() {
+	Register(ollamaProvider{})
+}
+
+func (ollamaProvider) Name() string         { return "ollama" }
+func (ollamaProvider) EnvKey() string       { return "" }
+func (ollamaProvider) DefaultModel() string { return "llama3" }
+
+func (ollamaProvider) ModelAliases() []string {
+	return []string{"llama3", "qwen", "deepseek"}
+}
+
+func (ollamaProvider) ResolveModel(alias string) string {
+	aliases := map[string]string{
+		"llama3":   "llama3",
+		"qwen":     "qwen3",
+		"deepseek": "deepseek-r1",
+	}
+	if id, ok := aliases[alias]; ok {
+		return id
+	}
+	return alias
+}
+
+// DefaultBaseURL returns the Ollama server used when base_url is not set.
+func (ollamaProvider) DefaultBaseURL() string { return OllamaHost("") }
+
+// OllamaHost returns the Ollama server URL from baseURL or OLLAMA_HOST.
+// A trailing /v1 from the OpenAI-compatible endpoint is dropped.
+func OllamaHost(baseURL string) string {
+	if baseURL == "" {
+		baseURL = os.Getenv("OLLAMA_HOST")
+	}
+	if baseURL == "" {
+		return "http://localhost:11434"
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")
+}
+
+// ollamaError is an error response from the Ollama API.
+type ollamaError struct {
+	Status  int
+	Message string
+}
+
+func (e *ollamaError) Error() string { return "Ollama API error: " + e.Message }
+
+// OllamaMissingModel reports whether err means the model isn't pulled yet.
+func OllamaMissingModel(err error) bool {
+	var oe *ollamaError
+	return errors.As(err, &oe) && oe.Status == http.StatusNotFound
+}
+
+// ollamaPost sends body as JSON to path and returns the response, turning
+// non-2xx statuses into an *ollamaError.
+func ollamaPost(ctx context.Context, baseURL, path string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	host := OllamaHost(baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, host+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ollama at %s: %w", host, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&e) != nil || e.Error == "" {
+			e.Error = resp.Status
+		}
+		return nil, &ollamaError{Status: resp.StatusCode, Message: e.Error}
+	}
+	return resp, nil
+}
+
+// chatBody builds the /api/chat request body for req.
+func (p ollamaProvider) chatBody(req Request, think bool) map[string]any {
+	modelID := p.ResolveModel(req.Model)
+	if modelID == "" {
+		modelID = p.ResolveModel(p.DefaultModel())
+	}
+
+	var messages []Message
+	if req.System != "" {
+		messages = append(messages, Message{Role: "system", Content: req.System})
+	}
+	messages = append(messages, req.Messages...)
+
+	body := map[string]any{
+		"model":    modelID,
+		"messages": messages,
+		"stream":   true,
+	}
+	if think {
+		body["think"] = true
+	}
+	if p.options.KeepAlive != "" {
+		body["keep_alive"] = p.options.KeepAlive
+	}
+	opts := map[string]any{}
+	for k, v := range p.options.Options {
+		opts[k] = v
+	}
+	if p.options.NumCtx > 0 {
+		opts["num_ctx"] = p.options.NumCtx
+	}
+	if len(opts) > 0 {
+		body["options"] = opts
+	}
+	return body
+}
+
+func (p ollamaProvider) Run(ctx context.Context, req Request) Stream {
+	return func(yield func(Event) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		resp, err := ollamaPost(ctx, req.BaseURL, "/api/chat", p.chatBody(req, req.Features.Thinking))
+		// Thinking is on by default in the config; retry plainly for models without it.
+		var oe *ollamaError
+		if errors.As(err, &oe) && req.Features.Thinking && strings.Contains(oe.Message, "does not support thinking") {
+			resp, err = ollamaPost(ctx, req.BaseURL, "/api/chat", p.chatBody(req, false))
+		}
+		if err != nil {
+			yield(Event{Kind: EventError, Err: err})
+			return
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var chunk struct {
+				Message struct {
+					Content  string `json:"content"`
+					Thinking string `json:"thinking"`
+				} `json:"message"`
+				Done            bool   `json:"done"`
+				DoneReason      string `json:"done_reason"`
+				PromptEvalCount int64  `json:"prompt_eval_count"`
+				EvalCount       int64  `json:"eval_count"`
+				Error           string `json:"error"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+				continue
+			}
+			var out []Event
+			switch {
+			case chunk.Error != "":
+				out = append(out, Event{Kind: EventError, Err: &ollamaError{Message: chunk.Error}})
+			case chunk.Message.Thinking != "":
+				out = append(out, Event{Kind: EventThinking, Text: chunk.Message.Thinking})
+			}
+			if chunk.Message.Content != "" {
+				out = append(out, Event{Kind: EventText, Text: chunk.Message.Content})
+			}
+			if chunk.Done {
+				out = append(out,
+					Event{Kind: EventUsage, Usage: Usage{InputTokens: chunk.PromptEvalCount, OutputTokens: chunk.EvalCount}},
+					Event{Kind: EventStop, StopReason: chunk.DoneReason},
+				)
+			}
+			for _, ev := range out {
+				if !yield(ev) || ev.Kind == EventError {
+					return
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(Event{Kind: EventError, Err: fmt.Errorf("Ollama API error: %v", err)})
+		}
+	}
+}
+
+func (p ollamaProvider) ListModels(ctx context.Context, _, baseURL string) ([]RemoteModel, error) {
+	tagsURL := OllamaHost(baseURL) + "/api/tags"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tagsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ollama at %s: %w", tagsURL, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+
+	var models []RemoteModel
+	for _, m := range result.Models {
+		models = append(models, RemoteModel{ID: m.Name})
+	}
+	return models, nil
+}
+
+// PullProgress is a status update from an Ollama model pull. Total and
+// Completed are byte counts of the layer being downloaded, if any.
+type PullProgress struct {
+	Status           string
+	Total, Completed int64
+}
+
+// PullOllamaModel downloads name via /api/pull, reporting each status
+// update to progress.
+func PullOllamaModel(ctx context.Context, baseURL, name string, progress func(PullProgress)) error {
+	resp, err := ollamaPost(ctx, baseURL, "/api/pull", map[string]any{"model": name, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	status := ""
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var ev struct {
+			Status    string `json:"status"`
+			Total     int64  `json:"total"`
+			Completed int64  `json:"completed"`
+			Error     string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		if ev.Error != "" {
+			return &ollamaError{Message: ev.Error}
+		}
+		progress(PullProgress{Status: ev.Status, Total: ev.Total, Completed: ev.Completed})
+		status = ev.Status
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Ollama API error: %v", err)
+	}
+	if status != "success" {
+		return fmt.Errorf("pull of %s did not complete", name)
+	}
+	return nil
+}
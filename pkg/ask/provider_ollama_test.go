@@ -0,0 +1,87 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOllamaHost(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		env     string
+		want    string
+	}{
+		{"", "", "http://localhost:11434"},
+		{"", "gpu-box:11434", "http://gpu-box:11434"},
+		{"http://localhost:11434/v1", "", "http://localhost:11434"},
+		{"https://ollama.example.com/", "gpu-box", "https://ollama.example.com"},
+	}
+	for _, tt := range tests {
+		t.Setenv("OLLAMA_HOST", tt.env)
+		if got := OllamaHost(tt.baseURL); got != tt.want {
+			t.Errorf("OllamaHost(%q) with OLLAMA_HOST=%q = %q, want %q", tt.baseURL, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestOllamaRun(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"","thinking":"hmm"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"hi"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":3}`)
+	}))
+	defer srv.Close()
+
+	p := ollamaProvider{options: OllamaOptions{
+		NumCtx:    8192,
+		KeepAlive: "10m",
+		Options:   map[string]any{"temperature": 0.2},
+	}}
+	req := UserRequest("hello")
+	req.Model = "qwen"
+	req.BaseURL = srv.URL + "/v1"
+	req.Features.Thinking = true
+
+	var text string
+	res, err := Collect(p.Run(context.Background(), req), func(s string) { text += s })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "hi" || res.Reasoning != "hmm" {
+		t.Errorf("text = %q, reasoning = %q", text, res.Reasoning)
+	}
+	if res.Usage.InputTokens != 12 || res.Usage.OutputTokens != 3 {
+		t.Errorf("usage = %+v", res.Usage)
+	}
+	if body["model"] != "qwen3" || body["think"] != true || body["keep_alive"] != "10m" {
+		t.Errorf("body = %v", body)
+	}
+	opts, _ := body["options"].(map[string]any)
+	if opts["num_ctx"] != float64(8192) || opts["temperature"] != 0.2 {
+		t.Errorf("options = %v", opts)
+	}
+}
+
+func TestOllamaMissingModel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"model \"qwen3\" not found, try pulling it first"}`)
+	}))
+	defer srv.Close()
+
+	req := UserRequest("hello")
+	req.BaseURL = srv.URL
+	_, err := Collect(ollamaProvider{}.Run(context.Background(), req), func(string) {})
+	if !OllamaMissingModel(err) {
+		t.Errorf("err = %v, want missing model", err)
+	}
+}
@@ -0,0 +1,575 @@
+package ask
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+// openaiCompatProvider implements Provider for OpenAI-compatible APIs.
+type openaiCompatProvider struct {
+	name       string
+	envKey     string
+	defaultURL string
+	headers    map[string]string
+	aliases    map[string]string
+	aliasList  []string
+	defaultMdl string
+
+	modelFilter func(id string) bool // hides non-chat models in ListModels
+	fallbacks   bool                 // "a,b" model lists become OpenRouter fallbacks
+	extraBody   map[string]any       // merged into every request body (provider_options)
+	azure       bool                 // Azure OpenAI: deployments, api-version, api-key or Entra ID
+	apiVersion  string               // Azure api-version (azure_api_version)
+}
+
+func init() {
+	Register(openaiCompatProvider{
+		name:       "openai",
+		envKey:     "OPENAI_API_KEY",
+		defaultURL: "https://api.openai.com/v1",
+		aliases: map[string]string{
+			"gpt4o":      "gpt-4o",
+			"gpt4o-mini": "gpt-4o-mini",
+			"o3-mini":    "o3-mini",
+			"o4-mini":    "o4-mini",
+		},
+		aliasList:   []string{"gpt4o", "gpt4o-mini", "o3-mini", "o4-mini"},
+		defaultMdl:  "gpt4o",
+		modelFilter: isChatModel,
+	})
+
+	Register(openaiCompatProvider{
+		name:       "xai",
+		envKey:     "XAI_API_KEY",
+		defaultURL: "https://api.x.ai/v1",
+		aliases: map[string]string{
+			"grok3":      "grok-3-latest",
+			"grok3-mini": "grok-3-mini-latest",
+		},
+		aliasList:  []string{"grok3", "grok3-mini"},
+		defaultMdl: "grok3",
+	})
+
+	Register(openaiCompatProvider{
+		name:       "deepseek",
+		envKey:     "DEEPSEEK_API_KEY",
+		defaultURL: "https://api.deepseek.com/v1",
+		aliases: map[string]string{
+			"v3": "deepseek-chat",
+			"r1": "deepseek-reasoner",
+		},
+		aliasList:  []string{"v3", "r1"},
+		defaultMdl: "v3",
+	})
+
+	Register(openaiCompatProvider{
+		name:       "mistral",
+		envKey:     "MISTRAL_API_KEY",
+		defaultURL: "https://api.mistral.ai/v1",
+		aliases: map[string]string{
+			"large":     "mistral-large-latest",
+			"medium":    "mistral-medium-latest",
+			"small":     "mistral-small-latest",
+			"codestral": "codestral-latest",
+		},
+		aliasList:   []string{"large", "medium", "small", "codestral"},
+		defaultMdl:  "medium",
+		modelFilter: excludeModels("embed", "moderation", "ocr"),
+	})
+
+	Register(openaiCompatProvider{
+		name:       "groq",
+		envKey:     "GROQ_API_KEY",
+		defaultURL: "https://api.groq.com/openai/v1",
+		aliases: map[string]string{
+			"llama70": "llama-3.3-70b-versatile",
+			"llama8":  "llama-3.1-8b-instant",
+			"gpt-oss": "openai/gpt-oss-120b",
+			"kimi":    "moonshotai/kimi-k2-instruct",
+		},
+		aliasList:   []string{"llama70", "llama8", "gpt-oss", "kimi"},
+		defaultMdl:  "llama70",
+		modelFilter: excludeModels("whisper", "tts", "guard"),
+	})
+
+	Register(openaiCompatProvider{
+		name:       "openrouter",
+		envKey:     "OPENROUTER_API_KEY",
+		defaultURL: "https://openrouter.ai/api/v1",
+		// App attribution headers shown on openrouter.ai.
+		headers: map[string]string{
+			"HTTP-Referer": "https://github.com/laurensent/ask",
+			"X-Title":      "ask",
+		},
+		aliases: map[string]string{
+			"auto":       "openrouter/auto",
+			"claude":     "anthropic/claude-sonnet-4.5",
+			"gpt":        "openai/gpt-4o",
+			"gemini-pro": "google/gemini-2.5-pro",
+			"llama":      "meta-llama/llama-3.3-70b-instruct",
+		},
+		aliasList:  []string{"auto", "claude", "gpt", "gemini-pro", "llama"},
+		defaultMdl: "auto",
+		fallbacks:  true,
+	})
+}
+
+func (p openaiCompatProvider) Name() string        { return p.name }
+func (p openaiCompatProvider) EnvKey() string       { return p.envKey }
+func (p openaiCompatProvider) DefaultModel() string { return p.defaultMdl }
+func (p openaiCompatProvider) ModelAliases() []string { return p.aliasList }
+
+func (p openaiCompatProvider) ResolveModel(alias string) string {
+	if id, ok := p.aliases[alias]; ok {
+		return id
+	}
+	return alias
+}
+
+// excludeModels returns a model filter that hides IDs containing any of parts.
+func excludeModels(parts ...string) func(string) bool {
+	return func(id string) bool {
+		for _, p := range parts {
+			if strings.Contains(id, p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// isChatModel filters OpenAI models to chat-capable ones.
+func isChatModel(id string) bool {
+	prefixes := []string{"gpt-", "o1", "o3", "o4", "chatgpt"}
+	for _, p := range prefixes {
+		if strings.HasPrefix(id, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p openaiCompatProvider) ListModels(ctx context.Context, apiKey, baseURL string) ([]RemoteModel, error) {
+	if p.azure {
+		return p.listAzureDeployments(ctx, apiKey, baseURL)
+	}
+
+	client, err := p.newClient(apiKey, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []RemoteModel
+	iter := client.Models.ListAutoPaging(ctx)
+	for iter.Next() {
+		m := iter.Current()
+		if p.modelFilter != nil && !p.modelFilter(m.ID) {
+			continue
+		}
+		models = append(models, RemoteModel{ID: m.ID})
+	}
+	if iter.Err() != nil {
+		return nil, fmt.Errorf("%s API error: %v", p.name, iter.Err())
+	}
+	return models, nil
+}
+
+// newClient returns an OpenAI SDK client for the provider's endpoint.
+func (p openaiCompatProvider) newClient(apiKey, baseURL string) (openai.Client, error) {
+	var opts []option.RequestOption
+	if p.azure {
+		azureOpts, err := p.azureOptions(apiKey, baseURL)
+		if err != nil {
+			return openai.Client{}, err
+		}
+		opts = azureOpts
+	} else {
+		if baseURL == "" {
+			baseURL = p.defaultURL
+		}
+		opts = append(opts, option.WithBaseURL(baseURL))
+		if apiKey != "" {
+			opts = append(opts, option.WithAPIKey(apiKey))
+		}
+	}
+	for k, v := range p.headers {
+		opts = append(opts, option.WithHeader(k, v))
+	}
+	return openai.NewClient(opts...), nil
+}
+
+// APIKeyOptional reports whether the provider can authenticate without a key.
+func (p openaiCompatProvider) APIKeyOptional() bool { return p.azure }
+
+// DefaultBaseURL returns the endpoint used when base_url is not set.
+func (p openaiCompatProvider) DefaultBaseURL() string { return p.defaultURL }
+
+// AcceptsTools reports that the provider supports function calling.
+func (openaiCompatProvider) AcceptsTools() bool { return true }
+
+// chatParams converts req into Chat Completions parameters.
+func (p openaiCompatProvider) chatParams(req Request) openai.ChatCompletionNewParams {
+	modelID := p.ResolveModel(req.Model)
+	if modelID == "" {
+		modelID = p.ResolveModel(p.defaultMdl)
+	}
+
+	var messages []openai.ChatCompletionMessageParamUnion
+	if req.System != "" {
+		messages = append(messages, openai.SystemMessage(req.System))
+	}
+	for _, m := range req.Messages {
+		for _, r := range m.ToolResults {
+			messages = append(messages, openai.ToolMessage(r.Content, r.CallID))
+		}
+		switch {
+		case m.Role == "assistant" && len(m.ToolCalls) > 0:
+			var msg openai.ChatCompletionAssistantMessageParam
+			if m.Content != "" {
+				msg.Content.OfString = openai.String(m.Content)
+			}
+			for _, c := range m.ToolCalls {
+				msg.ToolCalls = append(msg.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+					OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+						ID: c.ID,
+						Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+							Name:      c.Name,
+							Arguments: string(ToolArgs(c.Args)),
+						},
+					},
+				})
+			}
+			messages = append(messages, openai.ChatCompletionMessageParamUnion{OfAssistant: &msg})
+		case m.Role == "assistant":
+			messages = append(messages, openai.AssistantMessage(m.Content))
+		case m.Content != "" || len(m.ToolResults) == 0:
+			messages = append(messages, openai.UserMessage(m.Content))
+		}
+	}
+
+	params := openai.ChatCompletionNewParams{
+		Model:    openai.ChatModel(modelID),
+		Messages: messages,
+	}
+
+	for _, t := range req.Tools {
+		params.Tools = append(params.Tools, openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:        t.Name(),
+			Description: openai.String(t.Description()),
+			Parameters:  shared.FunctionParameters(t.Parameters()),
+		}))
+	}
+
+	extra := map[string]any{}
+	for k, v := range p.extraBody {
+		extra[k] = v
+	}
+	// OpenRouter tries each model in "models" in turn when one is unavailable.
+	if p.fallbacks && strings.Contains(modelID, ",") {
+		var models []string
+		for _, m := range strings.Split(modelID, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				models = append(models, p.ResolveModel(m))
+			}
+		}
+		params.Model = openai.ChatModel(models[0])
+		extra["models"] = models
+	}
+	if len(extra) > 0 {
+		params.SetExtraFields(extra)
+	}
+
+	// Other OpenAI models search through the Responses API web_search tool.
+	if req.Features.WebSearch && p.name == "openai" && strings.Contains(string(params.Model), "search") {
+		params.WebSearchOptions = openai.ChatCompletionNewParamsWebSearchOptions{
+			SearchContextSize: "medium",
+		}
+	}
+	return params
+}
+
+func (p openaiCompatProvider) Run(ctx context.Context, req Request) Stream {
+	params := p.chatParams(req)
+	// Agent mode stays on Chat Completions, which every compatible endpoint
+	// implements with the same function-calling format.
+	if modelID := string(params.Model); p.usesResponses(modelID) && len(req.Tools) == 0 {
+		return p.runResponses(ctx, req, modelID)
+	}
+	if len(req.Files) > 0 {
+		return errorStream(fmt.Errorf("%s: model %s does not accept file inputs", p.name, params.Model))
+	}
+
+	return func(yield func(Event) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		client, err := p.newClient(req.APIKey, req.BaseURL)
+		if err != nil {
+			yield(Event{Kind: EventError, Err: err})
+			return
+		}
+
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+			IncludeUsage: openai.Bool(true),
+		}
+
+		stream := client.Chat.Completions.NewStreaming(ctx, params)
+
+		var usage Usage
+		var stop string
+		var sources sourceList
+		var calls []ToolCall
+		for stream.Next() {
+			chunk := stream.Current()
+			if chunk.Usage.TotalTokens > 0 {
+				usage.InputTokens = chunk.Usage.PromptTokens
+				usage.OutputTokens = chunk.Usage.CompletionTokens
+			}
+			if len(chunk.Choices) == 0 {
+				continue
+			}
+			choice := chunk.Choices[0]
+			if choice.FinishReason != "" {
+				stop = choice.FinishReason
+			}
+			var out []Event
+			if r := reasoningDelta(choice.Delta); r != "" {
+				out = append(out, Event{Kind: EventThinking, Text: r})
+			}
+			for _, c := range citationsDelta(choice.Delta) {
+				if n, isNew := sources.cite(c.Title, c.URL); isNew {
+					out = append(out, Event{Kind: EventCitation, Source: sources.sources[n-1]})
+				}
+			}
+			// Tool calls arrive in pieces keyed by index: the ID and name
+			// first, then the JSON arguments a fragment at a time. They are
+			// sent once the stream ends.
+			for _, tc := range choice.Delta.ToolCalls {
+				for int(tc.Index) >= len(calls) {
+					calls = append(calls, ToolCall{})
+				}
+				c := &calls[tc.Index]
+				if tc.ID != "" {
+					c.ID = tc.ID
+				}
+				c.Name += tc.Function.Name
+				c.Args = append(c.Args, tc.Function.Arguments...)
+			}
+			if choice.Delta.Content != "" {
+				out = append(out, Event{Kind: EventText, Text: choice.Delta.Content})
+			}
+			for _, ev := range out {
+				if !yield(ev) {
+					return
+				}
+			}
+		}
+		if stream.Err() != nil {
+			yield(Event{Kind: EventError, Err: fmt.Errorf("%s API error: %v", p.name, stream.Err())})
+			return
+		}
+
+		for _, c := range calls {
+			c.Args = ToolArgs(c.Args)
+			if !yield(Event{Kind: EventToolCall, ToolCall: c}) {
+				return
+			}
+		}
+		if yield(Event{Kind: EventUsage, Usage: usage}) {
+			yield(Event{Kind: EventStop, StopReason: stop})
+		}
+	}
+}
+
+// reasoningDelta returns the reasoning text in a streamed delta. DeepSeek
+// (and vLLM, Groq) send it as reasoning_content, OpenRouter as reasoning.
+func reasoningDelta(delta openai.ChatCompletionChunkChoiceDelta) string {
+	for _, key := range []string{"reasoning_content", "reasoning"} {
+		if f, ok := delta.JSON.ExtraFields[key]; ok {
+			var text string
+			if json.Unmarshal([]byte(f.Raw()), &text) == nil {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// citationsDelta returns the url_citation annotations in a streamed delta,
+// sent by OpenAI search models and OpenRouter's web plugin.
+func citationsDelta(delta openai.ChatCompletionChunkChoiceDelta) []Source {
+	f, ok := delta.JSON.ExtraFields["annotations"]
+	if !ok {
+		return nil
+	}
+	var annotations []struct {
+		Type        string `json:"type"`
+		URLCitation struct {
+			Title string `json:"title"`
+			URL   string `json:"url"`
+		} `json:"url_citation"`
+	}
+	if json.Unmarshal([]byte(f.Raw()), &annotations) != nil {
+		return nil
+	}
+	var sources []Source
+	for _, a := range annotations {
+		if a.Type == "url_citation" {
+			sources = append(sources, Source{Title: a.URLCitation.Title, URL: a.URLCitation.URL})
+		}
+	}
+	return sources
+}
+
+// RunBatch submits reqs through the OpenAI Batch API and waits for the results.
+// Only the openai provider supports it.
+func (p openaiCompatProvider) RunBatch(ctx context.Context, reqs []Request, progress func(BatchProgress)) ([]BatchResponse, error) {
+	if p.name != "openai" {
+		return nil, fmt.Errorf("%s does not support the batch API", p.name)
+	}
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	client, err := p.newClient(reqs[0].APIKey, reqs[0].BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var input bytes.Buffer
+	for i, req := range reqs {
+		line, err := json.Marshal(map[string]any{
+			"custom_id": batchCustomID(i),
+			"method":    "POST",
+			"url":       "/v1/chat/completions",
+			"body":      p.chatParams(req),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode batch request: %w", err)
+		}
+		input.Write(append(line, '\n'))
+	}
+
+	file, err := client.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(&input, "batch.jsonl", "application/jsonl"),
+		Purpose: openai.FilePurposeBatch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s API error: %v", p.name, err)
+	}
+	batch, err := client.Batches.New(ctx, openai.BatchNewParams{
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+		Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+		InputFileID:      file.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s API error: %v", p.name, err)
+	}
+	return p.waitBatch(ctx, client, batch, len(reqs), progress)
+}
+
+// ResumeBatch waits for the results of an already submitted batch.
+func (p openaiCompatProvider) ResumeBatch(ctx context.Context, id string, reqs []Request, progress func(BatchProgress)) ([]BatchResponse, error) {
+	if p.name != "openai" {
+		return nil, fmt.Errorf("%s does not support the batch API", p.name)
+	}
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	client, err := p.newClient(reqs[0].APIKey, reqs[0].BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := client.Batches.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s API error: %v", p.name, err)
+	}
+	return p.waitBatch(ctx, client, batch, len(reqs), progress)
+}
+
+// waitBatch polls batch until it ends and reads its n results.
+func (p openaiCompatProvider) waitBatch(ctx context.Context, client openai.Client, batch *openai.Batch, n int, progress func(BatchProgress)) ([]BatchResponse, error) {
+	var err error
+	for {
+		switch batch.Status {
+		case openai.BatchStatusCompleted:
+		case openai.BatchStatusFailed, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+			return nil, fmt.Errorf("batch %s %s", batch.ID, batch.Status)
+		default:
+			progress(BatchProgress{ID: batch.ID, Done: int(batch.RequestCounts.Completed + batch.RequestCounts.Failed), Total: n})
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(batchPollInterval):
+			}
+			if batch, err = client.Batches.Get(ctx, batch.ID); err != nil {
+				return nil, fmt.Errorf("%s API error: %v", p.name, err)
+			}
+			continue
+		}
+		break
+	}
+
+	out := make([]BatchResponse, n)
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		if err := p.readBatchFile(ctx, client, fileID, out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// readBatchFile parses a batch output or error file into out, keyed by custom ID.
+func (p openaiCompatProvider) readBatchFile(ctx context.Context, client openai.Client, fileID string, out []BatchResponse) error {
+	resp, err := client.Files.Content(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("%s API error: %v", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line struct {
+			CustomID string `json:"custom_id"`
+			Response struct {
+				StatusCode int                   `json:"status_code"`
+				Body       openai.ChatCompletion `json:"body"`
+			} `json:"response"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		i, ok := parseBatchCustomID(line.CustomID, len(out))
+		if !ok {
+			continue
+		}
+		switch {
+		case line.Error != nil:
+			out[i].Err = fmt.Errorf("batch request failed: %s", line.Error.Message)
+		case line.Response.StatusCode != http.StatusOK:
+			out[i].Err = fmt.Errorf("batch request failed with status %d", line.Response.StatusCode)
+		case len(line.Response.Body.Choices) > 0:
+			out[i].Text = line.Response.Body.Choices[0].Message.Content
+			out[i].Usage = Usage{
+				InputTokens:  line.Response.Body.Usage.PromptTokens,
+				OutputTokens: line.Response.Body.Usage.CompletionTokens,
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,219 @@
+package ask
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+// usesResponses reports whether modelID is sent through the Responses API.
+// Only the openai provider has it; older models and the chat-only search,
+// audio and realtime variants stay on Chat Completions.
+func (p openaiCompatProvider) usesResponses(modelID string) bool {
+	if p.name != "openai" {
+		return false
+	}
+	for _, s := range []string{"search-preview", "audio", "realtime"} {
+		if strings.Contains(modelID, s) {
+			return false
+		}
+	}
+	for _, prefix := range []string{"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4", "codex-", "chatgpt-"} {
+		if strings.HasPrefix(modelID, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReasoningModel reports whether modelID accepts reasoning settings.
+func isReasoningModel(modelID string) bool {
+	if strings.Contains(modelID, "-chat") {
+		return false
+	}
+	for _, prefix := range []string{"o1", "o3", "o4", "gpt-5", "codex-"} {
+		if strings.HasPrefix(modelID, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsFiles reports whether file inputs can be sent to model.
+func (p openaiCompatProvider) AcceptsFiles(model string) bool {
+	if model == "" {
+		model = p.defaultMdl
+	}
+	return p.usesResponses(p.ResolveModel(model))
+}
+
+// responsesParams converts req into Responses API parameters. With a
+// previous response ID only the turns after the last assistant reply are
+// sent; the server already holds the rest of the conversation.
+func (p openaiCompatProvider) responsesParams(req Request, modelID string) (responses.ResponseNewParams, error) {
+	messages := req.Messages
+	if req.PreviousResponseID != "" {
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == "assistant" {
+				messages = messages[i+1:]
+				break
+			}
+		}
+	}
+
+	var input responses.ResponseInputParam
+	for i, m := range messages {
+		role := responses.EasyInputMessageRoleUser
+		if m.Role == "assistant" {
+			role = responses.EasyInputMessageRoleAssistant
+		}
+		// Files are attached to the latest user turn.
+		if i == len(messages)-1 && m.Role == "user" && len(req.Files) > 0 {
+			content := responses.ResponseInputMessageContentListParam{
+				responses.ResponseInputContentParamOfInputText(m.Content),
+			}
+			for _, path := range req.Files {
+				part, err := fileInput(path)
+				if err != nil {
+					return responses.ResponseNewParams{}, err
+				}
+				content = append(content, part)
+			}
+			input = append(input, responses.ResponseInputItemParamOfMessage(content, role))
+			continue
+		}
+		input = append(input, responses.ResponseInputItemParamOfMessage(m.Content, role))
+	}
+
+	params := responses.ResponseNewParams{
+		Model: shared.ResponsesModel(modelID),
+		Input: responses.ResponseNewParamsInputUnion{OfInputItemList: input},
+	}
+	if req.System != "" {
+		params.Instructions = openai.String(req.System)
+	}
+	if req.PreviousResponseID != "" {
+		params.PreviousResponseID = openai.String(req.PreviousResponseID)
+	}
+	if req.Features.WebSearch {
+		params.Tools = []responses.ToolUnionParam{{
+			OfWebSearch: &responses.WebSearchToolParam{Type: responses.WebSearchToolTypeWebSearch},
+		}}
+	}
+	if req.Features.Thinking && isReasoningModel(modelID) {
+		params.Reasoning = shared.ReasoningParam{Summary: shared.ReasoningSummaryAuto}
+	}
+	if len(p.extraBody) > 0 {
+		params.SetExtraFields(p.extraBody)
+	}
+	return params, nil
+}
+
+// fileInput reads a local file into an input part: images as input_image,
+// everything else (PDFs, text, spreadsheets) as input_file.
+func fileInput(path string) (responses.ResponseInputContentUnionParam, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return responses.ResponseInputContentUnionParam{}, fmt.Errorf("reading file input: %w", err)
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	dataURL := "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+
+	if strings.HasPrefix(mimeType, "image/") {
+		return responses.ResponseInputContentUnionParam{OfInputImage: &responses.ResponseInputImageParam{
+			Detail:   responses.ResponseInputImageDetailAuto,
+			ImageURL: openai.String(dataURL),
+		}}, nil
+	}
+	return responses.ResponseInputContentUnionParam{OfInputFile: &responses.ResponseInputFileParam{
+		Filename: openai.String(filepath.Base(path)),
+		FileData: openai.String(dataURL),
+	}}, nil
+}
+
+// runResponses streams a reply from the Responses API.
+func (p openaiCompatProvider) runResponses(ctx context.Context, req Request, modelID string) Stream {
+	return func(yield func(Event) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		client, err := p.newClient(req.APIKey, req.BaseURL)
+		if err != nil {
+			yield(Event{Kind: EventError, Err: err})
+			return
+		}
+		params, err := p.responsesParams(req, modelID)
+		if err != nil {
+			yield(Event{Kind: EventError, Err: err})
+			return
+		}
+
+		stream := client.Responses.NewStreaming(ctx, params)
+		for stream.Next() {
+			var out []Event
+			ev := stream.Current()
+			switch ev.Type {
+			case "response.output_text.delta":
+				out = append(out, Event{Kind: EventText, Text: ev.Delta})
+			case "response.reasoning_summary_text.delta":
+				out = append(out, Event{Kind: EventThinking, Text: ev.Delta})
+			case "response.reasoning_summary_part.done":
+				out = append(out, Event{Kind: EventThinking, Text: "\n\n"})
+			case "response.completed", "response.incomplete":
+				for _, s := range responseSources(ev.Response) {
+					out = append(out, Event{Kind: EventCitation, Source: s})
+				}
+				stop := string(ev.Response.Status)
+				if r := ev.Response.IncompleteDetails.Reason; r != "" {
+					stop = r
+				}
+				out = append(out,
+					Event{Kind: EventUsage, Usage: Usage{
+						InputTokens:  ev.Response.Usage.InputTokens,
+						OutputTokens: ev.Response.Usage.OutputTokens,
+					}},
+					Event{Kind: EventStop, StopReason: stop, ResponseID: ev.Response.ID},
+				)
+			case "response.failed":
+				out = append(out, Event{Kind: EventError, Err: fmt.Errorf("%s API error: %s", p.name, ev.Response.Error.Message)})
+			case "error":
+				out = append(out, Event{Kind: EventError, Err: fmt.Errorf("%s API error: %s", p.name, ev.Message)})
+			}
+			for _, ev := range out {
+				if !yield(ev) || ev.Kind == EventError {
+					return
+				}
+			}
+		}
+		if stream.Err() != nil {
+			yield(Event{Kind: EventError, Err: fmt.Errorf("%s API error: %v", p.name, stream.Err())})
+		}
+	}
+}
+
+// responseSources collects the url_citation annotations on a response's output text.
+func responseSources(r responses.Response) []Source {
+	var sources sourceList
+	for _, item := range r.Output {
+		for _, c := range item.Content {
+			for _, a := range c.Annotations {
+				if a.Type == "url_citation" {
+					sources.add(a.Title, a.URL)
+				}
+			}
+		}
+	}
+	return sources.sources
+}
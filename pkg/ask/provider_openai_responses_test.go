@@ -0,0 +1,116 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUsesResponses(t *testing.T) {
+	openai := providers["openai"].(openaiCompatProvider)
+	xai := providers["xai"].(openaiCompatProvider)
+	tests := []struct {
+		p     openaiCompatProvider
+		model string
+		want  bool
+	}{
+		{openai, "gpt-4o", true},
+		{openai, "gpt-5-mini", true},
+		{openai, "o4-mini", true},
+		{openai, "gpt-4o-search-preview", false},
+		{openai, "gpt-4o-audio-preview", false},
+		{openai, "gpt-3.5-turbo", false},
+		{xai, "grok-3-latest", false},
+	}
+	for _, tt := range tests {
+		if got := tt.p.usesResponses(tt.model); got != tt.want {
+			t.Errorf("%s.usesResponses(%q) = %v, want %v", tt.p.name, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestResponsesRun(t *testing.T) {
+	var body struct {
+		Model              string            `json:"model"`
+		Instructions       string            `json:"instructions"`
+		PreviousResponseID string            `json:"previous_response_id"`
+		Input              []json.RawMessage `json:"input"`
+		Tools              []map[string]any  `json:"tools"`
+		Reasoning          map[string]any    `json:"reasoning"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/responses" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range []string{
+			`{"type":"response.reasoning_summary_text.delta","delta":"thinking"}`,
+			`{"type":"response.output_text.delta","delta":"he"}`,
+			`{"type":"response.output_text.delta","delta":"llo"}`,
+			`{"type":"response.completed","response":{"id":"resp_2","output":[{"type":"message","content":[{"type":"output_text","text":"hello","annotations":[{"type":"url_citation","title":"Example","url":"https://example.com"}]}]}],"usage":{"input_tokens":20,"output_tokens":5}}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", ev)
+		}
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("some notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := providers["openai"].(openaiCompatProvider)
+	req := Request{
+		Messages: []Message{
+			{Role: "user", Content: "first"},
+			{Role: "assistant", Content: "reply"},
+			{Role: "user", Content: "follow-up"},
+		},
+		System:   "be brief",
+		Model:    "o4-mini",
+		APIKey:   "key",
+		BaseURL:  srv.URL,
+		Features: FeatureFlags{Thinking: true, WebSearch: true},
+		Files:    []string{file},
+
+		PreviousResponseID: "resp_1",
+	}
+
+	var text string
+	res, err := Collect(p.Run(context.Background(), req), func(s string) { text += s })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "hello" || res.Reasoning != "thinking" || res.ResponseID != "resp_2" {
+		t.Errorf("text = %q, reasoning = %q, id = %q", text, res.Reasoning, res.ResponseID)
+	}
+	if len(res.Sources) != 1 || res.Sources[0].URL != "https://example.com" {
+		t.Errorf("sources = %v", res.Sources)
+	}
+	if res.Usage.InputTokens != 20 || res.Usage.OutputTokens != 5 {
+		t.Errorf("usage = %+v", res.Usage)
+	}
+
+	if body.Model != "o4-mini" || body.Instructions != "be brief" || body.PreviousResponseID != "resp_1" {
+		t.Errorf("model = %q, instructions = %q, previous_response_id = %q", body.Model, body.Instructions, body.PreviousResponseID)
+	}
+	if len(body.Input) != 1 {
+		t.Fatalf("sent %d input items, want only the new turn", len(body.Input))
+	}
+	if in := string(body.Input[0]); !strings.Contains(in, `"follow-up"`) || !strings.Contains(in, `"filename":"notes.txt"`) {
+		t.Errorf("input = %s", in)
+	}
+	if len(body.Tools) != 1 || body.Tools[0]["type"] != "web_search" {
+		t.Errorf("tools = %v", body.Tools)
+	}
+	if body.Reasoning["summary"] != "auto" {
+		t.Errorf("reasoning = %v", body.Reasoning)
+	}
+}
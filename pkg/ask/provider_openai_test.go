@@ -0,0 +1,92 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+)
+
+func TestChatParamsExtras(t *testing.T) {
+	p := providers["openrouter"].(openaiCompatProvider)
+	p.extraBody = map[string]any{"provider": map[string]any{"order": []string{"anthropic"}}}
+
+	req := UserRequest("hi")
+	req.Model = "claude,gpt"
+	data, err := json.Marshal(p.chatParams(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Model    string         `json:"model"`
+		Models   []string       `json:"models"`
+		Provider map[string]any `json:"provider"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Model != "anthropic/claude-sonnet-4.5" {
+		t.Errorf("model = %q", body.Model)
+	}
+	if want := []string{"anthropic/claude-sonnet-4.5", "openai/gpt-4o"}; !reflect.DeepEqual(body.Models, want) {
+		t.Errorf("models = %v, want %v", body.Models, want)
+	}
+	if body.Provider == nil {
+		t.Error("provider_options not merged into body")
+	}
+}
+
+func TestReasoningDelta(t *testing.T) {
+	tests := []struct {
+		chunk string
+		want  string
+	}{
+		{`{"choices":[{"index":0,"delta":{"content":"","reasoning_content":"step 1"}}]}`, "step 1"},
+		{`{"choices":[{"index":0,"delta":{"reasoning":"why"}}]}`, "why"},
+		{`{"choices":[{"index":0,"delta":{"content":"answer"}}]}`, ""},
+	}
+	for _, tt := range tests {
+		var chunk openai.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(tt.chunk), &chunk); err != nil {
+			t.Fatal(err)
+		}
+		if got := reasoningDelta(chunk.Choices[0].Delta); got != tt.want {
+			t.Errorf("reasoningDelta(%s) = %q, want %q", tt.chunk, got, tt.want)
+		}
+	}
+}
+
+func TestProviderOptionsReachBody(t *testing.T) {
+	saved := providers["mistral"]
+	defer Register(saved)
+	if err := Configure(Options{ProviderOptions: map[string]map[string]any{"mistral": {"safe_prompt": true}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"content":"ok"}}]}`+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	req := UserRequest("hi")
+	req.APIKey = "test-key"
+	req.BaseURL = srv.URL
+	if _, err := Collect(providers["mistral"].Run(context.Background(), req), nil); err != nil {
+		t.Fatal(err)
+	}
+	if body["safe_prompt"] != true {
+		t.Errorf("safe_prompt not sent: %v", body)
+	}
+}
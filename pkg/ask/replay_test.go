@@ -0,0 +1,118 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/laurensent/ask/internal/cassette"
+)
+
+// Recorded exchanges with the real APIs. Re-record with ASK_RECORD=1 and
+// the provider's API key set.
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		cassette string
+		provider string
+		upstream string
+		model    string
+		prompt   string
+		features FeatureFlags
+		tools    []Tool
+
+		wantText      string
+		wantReasoning string
+		wantStop      string
+		wantUsage     Usage
+		wantSources   []Source
+		wantCalls     []ToolCall
+	}{
+		{
+			cassette: "anthropic_thinking", provider: "anthropic", upstream: "https://api.anthropic.com",
+			model: "sonnet", prompt: "What is the capital of Australia?", features: FeatureFlags{Thinking: true},
+			wantText:      "The capital of Australia is **Canberra**.",
+			wantReasoning: "The user wants the capital of Australia.",
+			wantStop:      "end_turn",
+			wantUsage:     Usage{InputTokens: 42, OutputTokens: 31},
+		},
+		{
+			cassette: "anthropic_tool_use", provider: "anthropic", upstream: "https://api.anthropic.com",
+			model: "haiku", prompt: "What is in pkg?", tools: []Tool{stubTool{}},
+			wantText:  "I'll list the directory.",
+			wantStop:  "tool_use",
+			wantUsage: Usage{InputTokens: 472, OutputTokens: 64},
+			wantCalls: []ToolCall{{ID: "toolu_01T1x1fJ34qAmk2tNTrN7Up6", Name: "list_dir", Args: json.RawMessage(`{"path": "pkg"}`)}},
+		},
+		{
+			cassette: "openai_responses", provider: "openai", upstream: "https://api.openai.com/v1",
+			model: "gpt4o", prompt: "How long is a haiku?",
+			wantText:  "Haiku are three lines of five, seven and five syllables.",
+			wantStop:  "completed",
+			wantUsage: Usage{InputTokens: 25, OutputTokens: 14},
+		},
+		{
+			cassette: "openai_chat_search", provider: "openai", upstream: "https://api.openai.com/v1",
+			model: "gpt-4o-mini-search-preview", prompt: "When was Go 1.25 released?", features: FeatureFlags{WebSearch: true},
+			wantText:    "Go 1.25 was released in August 2025.",
+			wantStop:    "stop",
+			wantUsage:   Usage{InputTokens: 9, OutputTokens: 11},
+			wantSources: []Source{{"Go 1.25 Release Notes", "https://go.dev/doc/go1.25"}},
+		},
+		{
+			cassette: "gemini_stream", provider: "gemini", upstream: "https://generativelanguage.googleapis.com",
+			model: "flash", prompt: "How many moons does Mars have?", features: FeatureFlags{Thinking: true, WebSearch: true},
+			wantText:      "Mars has two moons, Phobos and Deimos.",
+			wantReasoning: "**Counting Mars' moons**\n\nThe user asks how many moons Mars has. I'll confirm with a search.",
+			wantStop:      "STOP",
+			wantUsage:     Usage{InputTokens: 8, OutputTokens: 31},
+			wantSources:   []Source{{"nasa.gov", "https://science.nasa.gov/mars/moons/"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cassette, func(t *testing.T) {
+			p := providers[tt.provider]
+			req := UserRequest(tt.prompt)
+			req.Model = tt.model
+			req.Features = tt.features
+			req.Tools = tt.tools
+			req.APIKey = "test-key"
+			if cassette.Recording() {
+				req.APIKey = os.Getenv(p.EnvKey())
+			}
+			req.BaseURL = cassette.Server(t, filepath.Join("testdata", "cassettes", tt.cassette+".json"), tt.upstream)
+
+			res, err := Collect(p.Run(context.Background(), req), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cassette.Recording() {
+				return
+			}
+			if res.Text != tt.wantText || res.Reasoning != tt.wantReasoning || res.StopReason != tt.wantStop {
+				t.Errorf("text = %q, reasoning = %q, stop = %q", res.Text, res.Reasoning, res.StopReason)
+			}
+			if res.Usage != tt.wantUsage {
+				t.Errorf("usage = %+v, want %+v", res.Usage, tt.wantUsage)
+			}
+			if !reflect.DeepEqual(res.Sources, tt.wantSources) {
+				t.Errorf("sources = %v, want %v", res.Sources, tt.wantSources)
+			}
+			if !reflect.DeepEqual(res.ToolCalls, tt.wantCalls) {
+				t.Errorf("tool calls = %+v, want %+v", res.ToolCalls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// stubTool declares a tool to the model without running it.
+type stubTool struct{}
+
+func (stubTool) Name() string        { return "list_dir" }
+func (stubTool) Description() string { return "List a directory" }
+func (stubTool) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{"path": map[string]any{"type": "string"}}, "required": []string{"path"}}
+}
+func (stubTool) Run(context.Context, json.RawMessage) (string, error) { return "", nil }
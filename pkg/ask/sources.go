@@ -0,0 +1,87 @@
+package ask
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Source is a web page a response was grounded on.
+type Source struct {
+	Title string
+	URL   string
+}
+
+// sourceList collects sources in citation order, numbering each URL once.
+type sourceList struct {
+	sources []Source
+	index   map[string]int
+}
+
+// add records a source and returns its 1-based footnote number.
+func (l *sourceList) add(title, url string) int {
+	if url == "" {
+		return 0
+	}
+	if n, ok := l.index[url]; ok {
+		return n
+	}
+	if l.index == nil {
+		l.index = map[string]int{}
+	}
+	l.sources = append(l.sources, Source{Title: strings.TrimSpace(title), URL: url})
+	l.index[url] = len(l.sources)
+	return len(l.sources)
+}
+
+// cite records a source like add and reports whether it is new, in which
+// case it is the last element of l.sources.
+func (l *sourceList) cite(title, url string) (int, bool) {
+	before := len(l.sources)
+	n := l.add(title, url)
+	return n, len(l.sources) > before
+}
+
+// Footnotes renders sources as a numbered markdown list to append to an answer.
+func Footnotes(sources []Source) string {
+	if len(sources) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n---\n\n**Sources**\n\n")
+	for i, s := range sources {
+		if s.Title == "" || s.Title == s.URL {
+			fmt.Fprintf(&b, "%d. <%s>\n", i+1, s.URL)
+		} else {
+			fmt.Fprintf(&b, "%d. %s <%s>\n", i+1, escapeMarkdown(s.Title), s.URL)
+		}
+	}
+	return b.String()
+}
+
+// citationMarkers renders footnote numbers as inline markers, e.g. " [1][3]".
+func citationMarkers(nums []int) string {
+	var b strings.Builder
+	seen := map[int]bool{}
+	for _, n := range nums {
+		if !seen[n] {
+			seen[n] = true
+			fmt.Fprintf(&b, "[%d]", n)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return " " + b.String()
+}
+
+// escapeMarkdown escapes characters in s that markdown would treat as syntax.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>#|", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package ask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSourceList(t *testing.T) {
+	var l sourceList
+	nums := []int{
+		l.add("Go", "https://go.dev"),
+		l.add("Blog", "https://go.dev/blog"),
+		l.add("Go again", "https://go.dev"),
+		l.add("no url", ""),
+	}
+	if want := []int{1, 2, 1, 0}; !reflect.DeepEqual(nums, want) {
+		t.Errorf("numbers = %v, want %v", nums, want)
+	}
+	if want := []Source{{"Go", "https://go.dev"}, {"Blog", "https://go.dev/blog"}}; !reflect.DeepEqual(l.sources, want) {
+		t.Errorf("sources = %v, want %v", l.sources, want)
+	}
+}
+
+func TestFootnotes(t *testing.T) {
+	tests := []struct {
+		sources []Source
+		want    string
+	}{
+		{nil, ""},
+		{
+			[]Source{{"Go *1.25* release", "https://go.dev/doc/go1.25"}, {"", "https://example.com"}},
+			"\n\n---\n\n**Sources**\n\n1. Go \\*1.25\\* release <https://go.dev/doc/go1.25>\n2. <https://example.com>\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := Footnotes(tt.sources); got != tt.want {
+			t.Errorf("Footnotes(%v) = %q, want %q", tt.sources, got, tt.want)
+		}
+	}
+}
+
+func TestCitationMarkers(t *testing.T) {
+	tests := []struct {
+		nums []int
+		want string
+	}{
+		{nil, ""},
+		{[]int{2}, " [2]"},
+		{[]int{1, 3, 1}, " [1][3]"},
+	}
+	for _, tt := range tests {
+		if got := citationMarkers(tt.nums); got != tt.want {
+			t.Errorf("citationMarkers(%v) = %q, want %q", tt.nums, got, tt.want)
+		}
+	}
+}
+
+func TestAnthropicCitations(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"id":"m","type":"message","role":"assistant","content":[],"model":"claude","usage":{"input_tokens":10,"output_tokens":0}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"web_search_tool_result","tool_use_id":"t","content":[{"type":"web_search_result","title":"Go","url":"https://go.dev","encrypted_content":"x","page_age":""}]}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"citations_delta","citation":{"type":"web_search_result_location","title":"Go 1.25","url":"https://go.dev/doc/go1.25","cited_text":"...","encrypted_index":"x"}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Go 1.25 is out."}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":7}}`,
+		`{"type":"message_stop"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range events {
+			var e struct{ Type string }
+			json.Unmarshal([]byte(ev), &e)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, ev)
+		}
+	}))
+	defer srv.Close()
+
+	req := UserRequest("what's new in go")
+	req.APIKey = "key"
+	req.BaseURL = srv.URL
+	req.Features.WebSearch = true
+
+	var text string
+	res, err := Collect(anthropicProvider{}.Run(context.Background(), req), func(s string) { text += s })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Go 1.25 is out. [1]" {
+		t.Errorf("text = %q", text)
+	}
+	if want := []Source{{"Go 1.25", "https://go.dev/doc/go1.25"}}; !reflect.DeepEqual(res.Sources, want) {
+		t.Errorf("sources = %v, want %v", res.Sources, want)
+	}
+}
@@ -0,0 +1,40 @@
+package ask
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// Tool is a capability the model can call through function calling.
+// Tools return plain text.
+type Tool interface {
+	Name() string
+	Description() string
+	Parameters() map[string]any // JSON Schema object describing the arguments
+	Run(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// ToolCall is a model's request to run a tool.
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Args      json.RawMessage `json:"args"`
+	Signature []byte          `json:"signature,omitempty"` // Gemini thought signature, sent back as is
+}
+
+// ToolResult is the output of a tool call, sent back to the model.
+type ToolResult struct {
+	CallID  string `json:"call_id"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	IsError bool   `json:"is_error,omitempty"`
+}
+
+// ToolArgs returns args, or an empty object when the model sent none.
+func ToolArgs(args json.RawMessage) json.RawMessage {
+	if len(bytes.TrimSpace(args)) == 0 {
+		return json.RawMessage("{}")
+	}
+	return args
+}
@@ -0,0 +1,175 @@
+// Package cache stores API responses on disk, keyed by everything that
+// determines the answer, so repeated questions are answered without a
+// request.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Provider  string       `json:"provider"`
+	Model     string       `json:"model"`
+	Created   time.Time    `json:"created"`
+	Text      string       `json:"text"`
+	Reasoning string       `json:"reasoning,omitempty"`
+	Sources   []ask.Source `json:"sources,omitempty"`
+	Usage     ask.Usage    `json:"usage"`
+	Stop      string       `json:"stop_reason,omitempty"`
+}
+
+// Dir returns the directory holding cached responses.
+func Dir() string {
+	return filepath.Join(config.CacheDir(), "responses")
+}
+
+// Key returns the cache key for req sent to provider's model modelID.
+// params holds any other settings that change the answer, such as the
+// provider's options from the config.
+func Key(provider, modelID string, req ask.Request, params any) string {
+	data, _ := json.Marshal(struct {
+		Provider string
+		Model    string
+		BaseURL  string
+		System   string
+		Messages []ask.Message
+		Features ask.FeatureFlags
+		Params   any
+	}{provider, modelID, req.BaseURL, req.System, req.Messages, req.Features, params})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func path(key string) string {
+	return filepath.Join(Dir(), key+".json")
+}
+
+// Get returns the entry for key if there is one younger than ttl.
+func Get(key string, ttl time.Duration) (Entry, bool) {
+	data, err := os.ReadFile(path(key))
+	if err != nil {
+		return Entry{}, false
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil || time.Since(e.Created) > ttl {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Put stores e under key, stamping it with the current time.
+func Put(key string, e Entry) error {
+	e.Created = time.Now().UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(Dir(), 0700); err != nil {
+		return err
+	}
+	// Write and rename so concurrent readers never see a partial entry.
+	tmp, err := os.CreateTemp(Dir(), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path(key))
+}
+
+// Replay returns e as an event stream, as if the provider had sent it.
+func (e Entry) Replay() ask.Stream {
+	return func(yield func(ask.Event) bool) {
+		var events []ask.Event
+		if e.Reasoning != "" {
+			events = append(events, ask.Event{Kind: ask.EventThinking, Text: e.Reasoning})
+		}
+		events = append(events, ask.Event{Kind: ask.EventText, Text: e.Text})
+		for _, s := range e.Sources {
+			events = append(events, ask.Event{Kind: ask.EventCitation, Source: s})
+		}
+		events = append(events, ask.Event{Kind: ask.EventUsage, Usage: e.Usage}, ask.Event{Kind: ask.EventStop, StopReason: e.Stop})
+		for _, ev := range events {
+			if !yield(ev) {
+				return
+			}
+		}
+	}
+}
+
+// Stats summarizes the cache.
+type Stats struct {
+	Entries int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// Stat walks the cache directory and returns its statistics.
+func Stat() (Stats, error) {
+	var s Stats
+	files, err := entries()
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		s.Entries++
+		s.Bytes += info.Size()
+		if t := info.ModTime(); s.Oldest.IsZero() || t.Before(s.Oldest) {
+			s.Oldest = t
+		}
+		if t := info.ModTime(); t.After(s.Newest) {
+			s.Newest = t
+		}
+	}
+	return s, err
+}
+
+// Clear removes every cached response and returns how many there were.
+func Clear() (int, error) {
+	files, err := entries()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, f := range files {
+		if err := os.Remove(filepath.Join(Dir(), f.Name())); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// entries lists the cache files; a missing directory is an empty cache.
+func entries() ([]os.DirEntry, error) {
+	all, err := os.ReadDir(Dir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	var files []os.DirEntry
+	for _, f := range all {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".json") {
+			files = append(files, f)
+		}
+	}
+	return files, err
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+func TestKey(t *testing.T) {
+	req := ask.UserRequest("hello")
+	base := Key("anthropic", "claude-sonnet-4-5", req, nil)
+
+	withSystem := req
+	withSystem.System = "be brief"
+	withThinking := req
+	withThinking.Features.Thinking = true
+	other := ask.UserRequest("hello!")
+	withKey := req
+	withKey.APIKey = "secret"
+
+	for name, key := range map[string]string{
+		"provider": Key("openai", "claude-sonnet-4-5", req, nil),
+		"model":    Key("anthropic", "claude-opus-4-1", req, nil),
+		"system":   Key("anthropic", "claude-sonnet-4-5", withSystem, nil),
+		"features": Key("anthropic", "claude-sonnet-4-5", withThinking, nil),
+		"prompt":   Key("anthropic", "claude-sonnet-4-5", other, nil),
+		"params":   Key("anthropic", "claude-sonnet-4-5", req, map[string]any{"temperature": 0.2}),
+	} {
+		if key == base {
+			t.Errorf("changing the %s doesn't change the key", name)
+		}
+	}
+	if Key("anthropic", "claude-sonnet-4-5", withKey, nil) != base {
+		t.Error("the API key changes the key")
+	}
+}
+
+func TestPutGet(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if _, ok := Get("k", time.Hour); ok {
+		t.Fatal("empty cache returned an entry")
+	}
+	e := Entry{Provider: "mock", Model: "mock", Text: "Paris [1]", Sources: []ask.Source{{Title: "Paris", URL: "https://example.com"}}}
+	if err := Put("k", e); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := Get("k", time.Hour)
+	if !ok || got.Text != e.Text || len(got.Sources) != 1 {
+		t.Fatalf("Get = %+v, %v", got, ok)
+	}
+	if _, ok := Get("k", time.Nanosecond); ok {
+		t.Error("expired entry returned")
+	}
+
+	res, err := ask.Collect(got.Replay(), nil)
+	if err != nil || res.Text != e.Text || len(res.Sources) != 1 {
+		t.Errorf("replay = %+v, %v", res, err)
+	}
+
+	if s, err := Stat(); err != nil || s.Entries != 1 || s.Bytes == 0 {
+		t.Errorf("Stat = %+v, %v", s, err)
+	}
+	if n, err := Clear(); err != nil || n != 1 {
+		t.Errorf("Clear = %d, %v", n, err)
+	}
+	if s, _ := Stat(); s.Entries != 0 {
+		t.Errorf("%d entries after Clear", s.Entries)
+	}
+}
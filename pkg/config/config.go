@@ -0,0 +1,210 @@
+// Package config loads and saves the ask configuration file and resolves
+// per-provider settings from it.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+// Config holds user configuration loaded from the config file.
+type Config struct {
+	Mode         string `json:"mode"`
+	Provider     string `json:"provider,omitempty"`
+	APIKey       string `json:"api_key"`
+	BaseURL      string `json:"base_url,omitempty"`
+	DefaultModel string `json:"default_model"`
+	RawOutput    bool   `json:"raw_output"`
+	Theme        string `json:"theme"`
+	Thinking     bool   `json:"thinking"`
+	WebSearch    bool   `json:"web_search"`
+	ContextLimit int    `json:"context_limit,omitempty"`
+	CacheTTL     string `json:"cache_ttl,omitempty"`    // cache API responses this long, e.g. "24h"; empty disables
+	PromptCache  string `json:"prompt_cache,omitempty"` // provider prompt caching: off, auto (default) or 1h
+
+	CLIBackend  string                `json:"cli_backend,omitempty"`
+	CLIBackends map[string]CLIBackend `json:"cli_backends,omitempty"`
+
+	Providers       []ask.ProviderConfig       `json:"providers,omitempty"`
+	ProviderOptions map[string]map[string]any  `json:"provider_options,omitempty"`
+	AzureAPIVersion string                     `json:"azure_api_version,omitempty"`
+	Cloud           map[string]ask.CloudConfig `json:"cloud,omitempty"`
+	Ollama          ask.OllamaOptions          `json:"ollama,omitzero"`
+	Agent           Agent                      `json:"agent,omitzero"`
+	MCPServers      map[string]MCPServer       `json:"mcp_servers,omitempty"`
+	Serve           Serve                      `json:"serve,omitzero"`
+	MockScript      string                     `json:"mock_script,omitempty"`
+}
+
+// CLIBackend describes how to run an agentic CLI in single-shot mode.
+// Built-in backends can be overridden field by field from the cli_backends
+// config section, and new ones added there.
+type CLIBackend struct {
+	Binary       string   `json:"binary"`
+	Args         []string `json:"args,omitempty"`          // fixed leading args, e.g. a subcommand
+	PromptFlag   string   `json:"prompt_flag,omitempty"`   // empty: prompt is the last positional arg
+	ModelFlag    string   `json:"model_flag,omitempty"`    // empty: -m is ignored
+	ResumeFlag   string   `json:"resume_flag,omitempty"`   // empty: sessions can't be resumed
+	OutputFormat string   `json:"output_format,omitempty"` // "text" or "stream-json" (Claude Code events)
+	InstallURL   string   `json:"install_url,omitempty"`
+}
+
+// Agent is the agent section of the config.
+type Agent struct {
+	MaxIterations   int      `json:"max_iterations,omitempty"`   // model turns per query (default 10)
+	AllowedCommands []string `json:"allowed_commands,omitempty"` // run_command allow-list, replacing the default
+	AutoApprove     bool     `json:"auto_approve,omitempty"`     // run tool calls without asking
+}
+
+// MCPServer is one entry of mcp_servers in the config: either a command
+// that speaks MCP on stdio, or the URL of a Streamable HTTP server.
+type MCPServer struct {
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`     // extra environment; $VARS are expanded
+	URL     string            `json:"url,omitempty"`     // e.g. http://127.0.0.1:8931/mcp
+	Headers map[string]string `json:"headers,omitempty"` // sent with HTTP requests; $VARS are expanded
+	Trust   bool              `json:"trust,omitempty"`   // run this server's tools without asking
+}
+
+// Serve is the serve section of the config.
+type Serve struct {
+	// Clients maps a client name to the bearer token it sends. When set,
+	// requests without one of these tokens are rejected. Tokens may be
+	// $VARS, expanded from the environment.
+	Clients map[string]string `json:"clients,omitempty"`
+}
+
+// ResolvedProvider returns the configured provider name, defaulting to "anthropic".
+func (c Config) ResolvedProvider() string {
+	if c.Provider == "" {
+		return "anthropic"
+	}
+	return c.Provider
+}
+
+// ForProvider returns the config as it applies to the named provider.
+// The api_key and base_url settings belong to the configured provider only.
+func (c Config) ForProvider(name string) Config {
+	if name != c.ResolvedProvider() {
+		c.APIKey = ""
+		c.BaseURL = ""
+	}
+	c.Provider = name
+	return c
+}
+
+// RegisterProviders applies the providers, provider_options,
+// azure_api_version, ollama, cloud and mock_script settings to the
+// provider registry.
+func (c Config) RegisterProviders() error {
+	return ask.Configure(ask.Options{
+		Providers:       c.Providers,
+		ProviderOptions: c.ProviderOptions,
+		AzureAPIVersion: c.AzureAPIVersion,
+		Ollama:          c.Ollama,
+		Cloud:           c.Cloud,
+		MockScript:      c.MockScript,
+	})
+}
+
+// APIKeyFor returns the API key for p from its env var, falling back to the config.
+func (c Config) APIKeyFor(p ask.Provider) string {
+	if key := os.Getenv(p.EnvKey()); key != "" {
+		return key
+	}
+	return c.APIKey
+}
+
+// RequireAPIKey returns the API key for p, or an error if the provider needs one and none is set.
+func (c Config) RequireAPIKey(p ask.Provider) (string, error) {
+	apiKey := c.APIKeyFor(p)
+	if o, ok := p.(ask.OptionalKeyProvider); ok && o.APIKeyOptional() {
+		return apiKey, nil
+	}
+	if apiKey == "" && p.EnvKey() != "" {
+		return "", fmt.Errorf("API mode requires an API key. Set %q env var or \"api_key\" in config.", p.EnvKey())
+	}
+	return apiKey, nil
+}
+
+// Dir returns the ask configuration directory following XDG conventions.
+func Dir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ask")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "ask")
+}
+
+// DataDir returns the ask data directory following XDG conventions.
+func DataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "ask")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "ask")
+}
+
+// CacheDir returns the ask cache directory following XDG conventions.
+func CacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "ask")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "ask")
+}
+
+// Path returns the full path to the config file.
+func Path() string {
+	return filepath.Join(Dir(), "config.json")
+}
+
+// Load reads the config file and returns the parsed configuration.
+// Returns a zero-value config if the file doesn't exist or can't be parsed.
+func Load() Config {
+	var cfg Config
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		return cfg
+	}
+	_ = json.Unmarshal(data, &cfg)
+	return cfg
+}
+
+// Save writes cfg to the config file, creating its directory if needed.
+func Save(cfg Config) error {
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	data = append(data, '\n')
+	if err := os.WriteFile(Path(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// DefaultJSON returns a formatted default config.
+func DefaultJSON() []byte {
+	cfg := Config{
+		Mode:         "cli",
+		Provider:     "",
+		APIKey:       "",
+		BaseURL:      "",
+		DefaultModel: "",
+		RawOutput:    false,
+		Theme:        "auto",
+		Thinking:     true,
+		WebSearch:    false,
+	}
+	data, _ := json.MarshalIndent(cfg, "", "  ")
+	return append(data, '\n')
+}
@@ -1,4 +1,4 @@
-package main
+package config
 
 import (
 	"os"
@@ -9,20 +9,20 @@ import (
 func TestConfigDir(t *testing.T) {
 	t.Run("uses XDG_CONFIG_HOME when set", func(t *testing.T) {
 		t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg-config")
-		got := configDir()
+		got := Dir()
 		want := "/tmp/xdg-config/ask"
 		if got != want {
-			t.Errorf("configDir() = %q, want %q", got, want)
+			t.Errorf("Dir() = %q, want %q", got, want)
 		}
 	})
 
 	t.Run("falls back to ~/.config", func(t *testing.T) {
 		t.Setenv("XDG_CONFIG_HOME", "")
-		got := configDir()
+		got := Dir()
 		home, _ := os.UserHomeDir()
 		want := filepath.Join(home, ".config", "ask")
 		if got != want {
-			t.Errorf("configDir() = %q, want %q", got, want)
+			t.Errorf("Dir() = %q, want %q", got, want)
 		}
 	})
 }
@@ -30,20 +30,20 @@ func TestConfigDir(t *testing.T) {
 func TestDataDir(t *testing.T) {
 	t.Run("uses XDG_DATA_HOME when set", func(t *testing.T) {
 		t.Setenv("XDG_DATA_HOME", "/tmp/xdg-data")
-		got := dataDir()
+		got := DataDir()
 		want := "/tmp/xdg-data/ask"
 		if got != want {
-			t.Errorf("dataDir() = %q, want %q", got, want)
+			t.Errorf("DataDir() = %q, want %q", got, want)
 		}
 	})
 
 	t.Run("falls back to ~/.local/share", func(t *testing.T) {
 		t.Setenv("XDG_DATA_HOME", "")
-		got := dataDir()
+		got := DataDir()
 		home, _ := os.UserHomeDir()
 		want := filepath.Join(home, ".local", "share", "ask")
 		if got != want {
-			t.Errorf("dataDir() = %q, want %q", got, want)
+			t.Errorf("DataDir() = %q, want %q", got, want)
 		}
 	})
 }
@@ -51,9 +51,9 @@ func TestDataDir(t *testing.T) {
 func TestLoadConfig(t *testing.T) {
 	t.Run("returns zero value for missing file", func(t *testing.T) {
 		t.Setenv("XDG_CONFIG_HOME", "/tmp/nonexistent-ccq-config-dir")
-		cfg := loadConfig()
+		cfg := Load()
 		if cfg.DefaultModel != "" || cfg.RawOutput != false {
-			t.Errorf("loadConfig() = %+v, want zero value", cfg)
+			t.Errorf("Load() = %+v, want zero value", cfg)
 		}
 	})
 
@@ -64,7 +64,7 @@ func TestLoadConfig(t *testing.T) {
 		os.MkdirAll(cqDir, 0755)
 		os.WriteFile(filepath.Join(cqDir, "config.json"), []byte(`{"default_model":"opus","raw_output":true}`), 0644)
 
-		cfg := loadConfig()
+		cfg := Load()
 		if cfg.DefaultModel != "opus" {
 			t.Errorf("DefaultModel = %q, want %q", cfg.DefaultModel, "opus")
 		}
@@ -80,7 +80,7 @@ func TestLoadConfig(t *testing.T) {
 		os.MkdirAll(cqDir, 0755)
 		os.WriteFile(filepath.Join(cqDir, "config.json"), []byte(`not json`), 0644)
 
-		cfg := loadConfig()
+		cfg := Load()
 		if cfg.DefaultModel != "" {
 			t.Errorf("DefaultModel = %q, want empty for invalid json", cfg.DefaultModel)
 		}
@@ -88,11 +88,11 @@ func TestLoadConfig(t *testing.T) {
 }
 
 func TestDefaultConfigJSON(t *testing.T) {
-	data := defaultConfigJSON()
+	data := DefaultJSON()
 	if len(data) == 0 {
-		t.Error("defaultConfigJSON() returned empty data")
+		t.Error("DefaultJSON() returned empty data")
 	}
 	if data[len(data)-1] != '\n' {
-		t.Error("defaultConfigJSON() should end with newline")
+		t.Error("DefaultJSON() should end with newline")
 	}
 }
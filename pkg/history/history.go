@@ -0,0 +1,124 @@
+// Package history stores the query history and saved chat sessions in the
+// ask data directory.
+package history
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/laurensent/ask/pkg/config"
+)
+
+// TimeFormat is the layout of history timestamps.
+const TimeFormat = "2006-01-02 15:04:05"
+
+// Path returns the location of the history file.
+func Path() string {
+	return filepath.Join(config.DataDir(), "history")
+}
+
+// Add appends a query to the history file, along with the claude session
+// ID when the query ran in CLI mode.
+func Add(query, sessionID string) {
+	if query == "" {
+		return
+	}
+	p := Path()
+	_ = os.MkdirAll(filepath.Dir(p), 0755)
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	escaped := strings.ReplaceAll(query, "\\", "\\\\")
+	escaped = strings.ReplaceAll(escaped, "\n", "\\n")
+	escaped = strings.ReplaceAll(escaped, "\t", "\\t")
+	if sessionID != "" {
+		escaped += "\t" + sessionID
+	}
+	fmt.Fprintf(f, "%s\t%s\n", time.Now().Format(TimeFormat), escaped)
+}
+
+// Entry is one query in the history file.
+type Entry struct {
+	Time      string
+	Query     string
+	SessionID string // claude session to resume; empty for API mode
+}
+
+// parseLine parses a "time<TAB>query[<TAB>session]" history line.
+func parseLine(line string) (Entry, bool) {
+	parts := strings.SplitN(line, "\t", 3)
+	if len(parts) < 2 {
+		return Entry{}, false
+	}
+	query := parts[1]
+	query = strings.ReplaceAll(query, "\\t", "\t")
+	query = strings.ReplaceAll(query, "\\n", "\n")
+	query = strings.ReplaceAll(query, "\\\\", "\\")
+	e := Entry{Time: parts[0], Query: query}
+	if len(parts) == 3 {
+		e.SessionID = parts[2]
+	}
+	return e, true
+}
+
+// Load returns all history entries, oldest first.
+func Load() ([]Entry, error) {
+	f, err := os.Open(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if e, ok := parseLine(scanner.Text()); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// LastSessionID returns the session ID of the most recent resumable query.
+func LastSessionID() (string, error) {
+	entries, err := Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to read history: %w", err)
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].SessionID != "" {
+			return entries[i].SessionID, nil
+		}
+	}
+	return "", fmt.Errorf("no resumable session in history (CLI mode queries record one)")
+}
+
+// ExpandSessionID completes a session ID prefix from history. Unknown IDs
+// are returned unchanged so claude can resolve them itself.
+func ExpandSessionID(id string) string {
+	entries, _ := Load()
+	for i := len(entries) - 1; i >= 0; i-- {
+		if strings.HasPrefix(entries[i].SessionID, id) {
+			return entries[i].SessionID
+		}
+	}
+	return id
+}
+
+// Clear removes the history file.
+func Clear() error {
+	if err := os.Remove(Path()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+func TestParseHistoryLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   Entry
+		wantOK bool
+	}{
+		{"2026-01-01 10:00:00\thow to rebase", Entry{Time: "2026-01-01 10:00:00", Query: "how to rebase"}, true},
+		{"2026-01-01 10:00:00\tline1\\nline2\\tx", Entry{Time: "2026-01-01 10:00:00", Query: "line1\nline2\tx"}, true},
+		{"2026-01-01 10:00:00\tq\tabc-123", Entry{Time: "2026-01-01 10:00:00", Query: "q", SessionID: "abc-123"}, true},
+		{"garbage", Entry{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseLine(tt.line)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseLine(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSessionTitle(t *testing.T) {
+	s := NewSession("mock", "mock")
+	s.Messages = []ask.Message{{Role: "user", Content: strings.Repeat("é", 70) + "\nmore"}}
+	title := s.Title()
+	if !utf8.ValidString(title) || title != strings.Repeat("é", 57)+"..." {
+		t.Errorf("Title() = %q", title)
+	}
+	if other := NewSession("mock", "mock"); other.ID == s.ID {
+		t.Errorf("two sessions share the ID %s", s.ID)
+	}
+}
@@ -0,0 +1,149 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
+)
+
+const sessionIDFormat = "20060102-150405"
+
+// Session is a persisted chat conversation that can be resumed later.
+type Session struct {
+	ID       string        `json:"id"`
+	Created  time.Time     `json:"created"`
+	Updated  time.Time     `json:"updated"`
+	Provider string        `json:"provider"`
+	Model    string        `json:"model"`
+	System   string        `json:"system,omitempty"`
+	Messages []ask.Message `json:"messages"`
+
+	// ResponseID is the provider's ID for the last reply, sent as
+	// previous_response_id so follow-ups don't resend the transcript.
+	ResponseID string `json:"response_id,omitempty"`
+}
+
+// SessionsDir returns the directory chat sessions are saved in.
+func SessionsDir() string {
+	return filepath.Join(config.DataDir(), "sessions")
+}
+
+// NewSession starts an empty session, identified by the current time and
+// a random suffix, so sessions started in the same second don't collide.
+func NewSession(provider, model string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:       fmt.Sprintf("%s-%04x", now.Format(sessionIDFormat), rand.N(0x10000)),
+		Created:  now,
+		Updated:  now,
+		Provider: provider,
+		Model:    model,
+	}
+}
+
+// Title returns a one-line summary of the session (its first user message).
+func (s *Session) Title() string {
+	for _, m := range s.Messages {
+		if m.Role == "user" {
+			line := []rune(strings.SplitN(m.Content, "\n", 2)[0])
+			if len(line) > 60 {
+				return string(line[:57]) + "..."
+			}
+			return string(line)
+		}
+	}
+	return "(empty)"
+}
+
+// Save writes the session to the sessions directory. Empty sessions are not saved.
+func (s *Session) Save() error {
+	if len(s.Messages) == 0 {
+		return nil
+	}
+	s.Updated = time.Now()
+	if err := os.MkdirAll(SessionsDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	return os.WriteFile(filepath.Join(SessionsDir(), s.ID+".json"), data, 0644)
+}
+
+// Markdown renders the session as a markdown transcript.
+func (s *Session) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Chat %s\n\n", s.ID)
+	fmt.Fprintf(&b, "_%s · %s_\n\n", s.Provider, s.Model)
+	if s.System != "" {
+		fmt.Fprintf(&b, "**System:** %s\n\n", s.System)
+	}
+	for _, m := range s.Messages {
+		if m.Role == "user" {
+			b.WriteString("## You\n\n")
+		} else {
+			b.WriteString("## Assistant\n\n")
+		}
+		b.WriteString(strings.TrimSpace(m.Content) + "\n\n")
+	}
+	return b.String()
+}
+
+// ListSessions returns all saved sessions, most recently updated first.
+func ListSessions() ([]*Session, error) {
+	files, err := filepath.Glob(filepath.Join(SessionsDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var sessions []*Session
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var s Session
+		if json.Unmarshal(data, &s) != nil {
+			continue
+		}
+		sessions = append(sessions, &s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Updated.After(sessions[j].Updated) })
+	return sessions, nil
+}
+
+// LoadSession loads a saved session by ID. The ID "last" selects the
+// most recently updated session.
+func LoadSession(id string) (*Session, error) {
+	if id == "last" {
+		sessions, err := ListSessions()
+		if err != nil {
+			return nil, err
+		}
+		if len(sessions) == 0 {
+			return nil, fmt.Errorf("no saved chat sessions")
+		}
+		return sessions[0], nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(SessionsDir(), id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("chat session %q not found (see: ask chat --list)", id)
+		}
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %q: %w", id, err)
+	}
+	return &s, nil
+}
@@ -1,17 +1,25 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"os"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
 )
 
 // renderMarkdown renders markdown content to ANSI-styled terminal output.
 func renderMarkdown(content string) (string, error) {
-	width := getTermWidth()
+	return renderMarkdownWidth(content, getTermWidth())
+}
 
+// renderMarkdownWidth renders markdown content wrapped to the given width.
+func renderMarkdownWidth(content string, width int) (string, error) {
 	var styleOpt glamour.TermRendererOption
-	cfg := loadConfig()
+	cfg := config.Load()
 	switch cfg.Theme {
 	case "dark", "light", "dracula", "pink", "ascii", "notty":
 		styleOpt = glamour.WithStandardStyle(cfg.Theme)
@@ -32,3 +40,70 @@ func renderMarkdown(content string) (string, error) {
 	}
 	return strings.TrimSpace(out) + "\n", nil
 }
+
+// renderResponse displays a provider's response through runStreaming, with
+// the reasoning dimmed on stderr above the answer (when showThinking is
+// set) and source footnotes below it. It returns the collected result.
+func renderResponse(events ask.Stream, showThinking bool) (ask.Result, error) {
+	var res ask.Result
+	err := runStreaming(func(emit func(string)) error {
+		var err error
+		res, err = ask.Collect(events, emit)
+		// Rendered output is printed after this returns, so the reasoning
+		// lands above the answer.
+		if res.Reasoning != "" && showThinking && !rawOutput && isStdoutTerminal() {
+			fmt.Fprintln(os.Stderr, wizardDim.Render(strings.TrimSpace(res.Reasoning)))
+			fmt.Fprintln(os.Stderr)
+		}
+		if err == nil {
+			emit(ask.Footnotes(res.Sources))
+		}
+		return err
+	})
+	return res, err
+}
+
+// runStreaming is a shared helper that manages the spinner and buffer/render
+// lifecycle for streaming provider responses. The streamFn callback receives
+// a function to call with each text chunk.
+func runStreaming(streamFn func(emit func(text string)) error) error {
+	sp := startSpinner()
+
+	needRender := !rawOutput && isStdoutTerminal()
+	var outBuf bytes.Buffer
+	spinnerStopped := false
+
+	emit := func(text string) {
+		if needRender {
+			outBuf.WriteString(text)
+		} else {
+			if !spinnerStopped {
+				sp.Stop()
+				spinnerStopped = true
+			}
+			fmt.Print(text)
+		}
+	}
+
+	err := streamFn(emit)
+	sp.Stop()
+
+	if err != nil {
+		return err
+	}
+
+	if needRender {
+		raw := outBuf.String()
+		if raw == "" {
+			return nil
+		}
+		rendered, renderErr := renderMarkdown(strings.TrimSpace(raw))
+		if renderErr != nil {
+			fmt.Print(raw)
+			return nil
+		}
+		fmt.Print(rendered)
+	}
+
+	return nil
+}
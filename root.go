@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
+	"github.com/laurensent/ask/pkg/history"
 	"github.com/spf13/cobra"
 )
 
@@ -14,22 +20,65 @@ var dryRun bool
 var rawOutput bool
 var thinkFlag bool
 var searchFlag bool
-var cfg appConfig
+var interactiveFlag bool
+var editorFlag bool
+var compareSpec string
+var chunkFlag bool
+var truncateMode string
+var continueFlag bool
+var resumeID string
+var fileFlags []string
+var sourcesOnly bool
+var agentFlag bool
+var noCache bool
+var cacheTTL time.Duration
+var promptCacheFlag string
+var promptCache ask.PromptCache
+var cfg config.Config
 
 var rootCmd = &cobra.Command{
 	Use:   "ask [prompt...]",
 	Short: "Quick invoke LLMs from terminal with markdown rendering",
-	Long:  "ask is a fast CLI tool for single-shot LLM queries from the terminal.\nIt supports multiple providers (Anthropic, OpenAI, Gemini, xAI, Ollama),\nrendered markdown output, pipe input, and query history.",
+	Long:  "ask is a fast CLI tool for single-shot LLM queries from the terminal.\nIt supports multiple providers (Anthropic, OpenAI, Gemini, xAI, Ollama,\nDeepSeek, Mistral, Groq, OpenRouter and any OpenAI-compatible endpoint),\nrendered markdown output, pipe input, and query history.",
 	Example: `  ask "how to rebase"
   ask -m opus "complex question"
   ask --raw "question"             # skip markdown rendering
   ask                              # interactive mode (no shell escaping needed)
+  ask -i                           # full-screen chat session
+  ask -e                           # compose the prompt in $EDITOR
+  ask -c "and in Python?"          # continue the last claude session
+  ask --compare sonnet,gpt4o,flash "question"
+  ask -f report.pdf "summarize this"  # file input (openai)
+  ask --sources-only "latest Go release notes"
+  ask --agent "which file in this repo is largest?"
   git diff | ask "review this code"
-  cat error.log | ask "analyze this error"`,
+  cat error.log | ask "analyze this error"
+  cat huge.log | ask --chunk "list every distinct error"`,
 	Version:       version,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if interactiveFlag {
+			return runChat("")
+		}
+
+		resume, err := resumeSession()
+		if err != nil {
+			return err
+		}
+		if resume != "" && (compareSpec != "" || chunkFlag) {
+			return fmt.Errorf("-c and --resume can't be combined with --compare or --chunk")
+		}
+		if len(fileFlags) > 0 && (cfg.Mode != "api" || compareSpec != "" || chunkFlag) {
+			return fmt.Errorf("--file needs API mode and can't be combined with --compare or --chunk")
+		}
+		if sourcesOnly && (cfg.Mode != "api" || compareSpec != "" || chunkFlag) {
+			return fmt.Errorf("--sources-only needs API mode and can't be combined with --compare or --chunk")
+		}
+		if agentFlag && (cfg.Mode != "api" || compareSpec != "" || chunkFlag || sourcesOnly || len(fileFlags) > 0) {
+			return fmt.Errorf("--agent needs API mode and can't be combined with --compare, --chunk, --sources-only or --file")
+		}
+
 		var pipeContent string
 		if isPiped() {
 			var err error
@@ -39,7 +88,30 @@ var rootCmd = &cobra.Command{
 			}
 		}
 
-		prompt := buildPrompt(args, pipeContent)
+		question := strings.Join(args, " ")
+		if editorFlag {
+			edited, err := editText(editorTemplate(question, pipeContent))
+			if err != nil {
+				return err
+			}
+			if edited == "" {
+				return nil // empty buffer aborts, like git commit
+			}
+			question = edited
+		}
+
+		if pipeContent != "" && !chunkFlag && compareSpec == "" {
+			var err error
+			pipeContent, chunkFlag, err = fitPipeContent(pipeContent)
+			if errors.Is(err, errCanceled) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		prompt := buildPrompt([]string{question}, pipeContent)
 		if prompt == "" {
 			// Interactive mode: read prompt from stdin (bypass shell parsing)
 			var err error
@@ -51,11 +123,20 @@ var rootCmd = &cobra.Command{
 				return cmd.Help()
 			}
 		}
-		saveHistory(prompt)
-		if cfg.Mode == "api" {
-			return runAPI(prompt, model, cfg)
+		if cfg.Mode != "api" && compareSpec == "" && !chunkFlag {
+			return runCLIWithHistory(prompt, model, resume)
+		}
+		history.Add(prompt, "")
+		if chunkFlag && pipeContent != "" {
+			return runChunked(question, pipeContent)
+		}
+		if compareSpec != "" {
+			return runCompare(prompt, compareSpec)
 		}
-		return runClaude(prompt, model)
+		if agentFlag {
+			return runAgent(prompt, model, cfg)
+		}
+		return runAPI(prompt, model, cfg)
 	},
 }
 
@@ -88,14 +169,30 @@ var configCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&model, "model", "m", "", "model alias or full ID (provider-specific: sonnet, gpt4o, flash, etc.)")
-	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the claude command instead of running it")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the command or request instead of running it")
 	rootCmd.PersistentFlags().BoolVar(&rawOutput, "raw", false, "output raw text without markdown rendering")
 	rootCmd.PersistentFlags().BoolVar(&thinkFlag, "think", false, "enable extended thinking")
 	rootCmd.PersistentFlags().BoolVar(&searchFlag, "search", false, "enable web search")
+	rootCmd.Flags().StringVar(&compareSpec, "compare", "", "compare answers from several models (e.g. sonnet,gpt4o,openai:o3-mini)")
+	rootCmd.Flags().BoolVar(&chunkFlag, "chunk", false, "split piped input that exceeds the context window and map-reduce over it")
+	rootCmd.Flags().StringVar(&truncateMode, "truncate", "", "keep the head or tail of piped input that exceeds the context window (head|tail)")
+	rootCmd.Flags().BoolVarP(&editorFlag, "editor", "e", false, "compose the prompt in $EDITOR")
+	rootCmd.Flags().BoolVarP(&continueFlag, "continue", "c", false, "continue the most recent claude session from history (CLI mode)")
+	rootCmd.Flags().StringVar(&resumeID, "resume", "", "resume a claude session by ID or ID prefix (CLI mode)")
+	rootCmd.Flags().StringArrayVarP(&fileFlags, "file", "f", nil, "attach a file (PDF, image, text) as input; repeatable (openai)")
+	rootCmd.Flags().BoolVar(&sourcesOnly, "sources-only", false, "print only the URLs web search cited (implies --search)")
+	rootCmd.Flags().BoolVar(&agentFlag, "agent", false, "let the model read files, list directories, grep and run allow-listed commands (API mode)")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "don't read or write the response cache")
+	rootCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 0, "answer from the response cache when it has a reply younger than this (e.g. 24h; API mode)")
+	rootCmd.Flags().StringVar(&promptCacheFlag, "prompt-cache", "auto", "cache large piped input and system prompts on the provider (off|auto|1h; Anthropic)")
+	rootCmd.Flags().BoolVarP(&interactiveFlag, "interactive", "i", false, "open a full-screen chat session (same as: ask chat)")
 
 	// Apply config defaults before command execution
-	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		cfg = loadConfig()
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		cfg = config.Load()
+		if err := cfg.RegisterProviders(); err != nil {
+			return err
+		}
 		if !cmd.Flags().Changed("model") && cfg.DefaultModel != "" {
 			model = cfg.DefaultModel
 		}
@@ -108,12 +205,34 @@ func init() {
 		if !cmd.Flags().Changed("search") {
 			searchFlag = cfg.WebSearch
 		}
+		if !cmd.Flags().Changed("cache-ttl") && cfg.CacheTTL != "" {
+			d, err := time.ParseDuration(cfg.CacheTTL)
+			if err != nil {
+				return fmt.Errorf("cache_ttl in config: %w", err)
+			}
+			cacheTTL = d
+		}
+		if !cmd.Flags().Changed("prompt-cache") && cfg.PromptCache != "" {
+			promptCacheFlag = cfg.PromptCache
+		}
+		var err error
+		if promptCache, err = ask.ParsePromptCache(promptCacheFlag); err != nil {
+			return err
+		}
+		return nil
 	}
 
 	historyCmd.AddCommand(historyClearCmd)
 	rootCmd.AddCommand(historyCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(modelsCmd)
+	rootCmd.AddCommand(chatCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(mcpCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(evalCmd)
+	cacheCmd.AddCommand(cacheStatsCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
 
 	rootCmd.SetVersionTemplate("ask version {{.Version}}\n")
 
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/laurensent/ask/pkg/ask"
+	"github.com/laurensent/ask/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var serveListen string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local OpenAI-compatible API server",
+	Long: `Serve /v1/chat/completions and /v1/models on a local address, routing each
+request to a provider by its model name: "anthropic:sonnet", an alias such as
+"flash", or a model ID of the configured provider. Streaming (SSE) is
+supported. Editors and scripts that speak the OpenAI API can then use any
+provider with the keys in your ask config.
+
+Each request is logged to stderr and appended to serve-usage.jsonl in the
+ask data directory, attributed to the client named in serve.clients (by its
+bearer token) or to the remote address.`,
+	Example: `  ask serve
+  ask serve --listen 127.0.0.1:9000
+  curl localhost:8088/v1/chat/completions -H 'Content-Type: application/json' -d '{"model":"haiku","messages":[{"role":"user","content":"hi"}]}'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServe(serveListen)
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8088", "address to listen on")
+}
+
+// runServe serves until interrupted, then prints usage per client.
+func runServe(addr string) error {
+	if host, _, err := net.SplitHostPort(addr); err == nil && !isLoopback(host) && len(cfg.Serve.Clients) == 0 {
+		return fmt.Errorf("listening on %s would let anyone who can reach it use your API keys; set serve.clients in the ask config first", addr)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s := newProxyServer(cfg)
+	srv := &http.Server{Handler: s}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving the OpenAI API on http://%s/v1 (Ctrl-C to stop)\n", ln.Addr())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	s.printTotals()
+	return nil
+}
+
+// proxyServer translates OpenAI API requests into provider requests.
+type proxyServer struct {
+	cfg     config.Config
+	mux     *http.ServeMux
+	logPath string
+
+	mu     sync.Mutex
+	totals map[string]*clientUsage
+}
+
+// clientUsage accumulates the requests and tokens of one client.
+type clientUsage struct {
+	Requests     int
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// usageRecord is one line of serve-usage.jsonl.
+type usageRecord struct {
+	Time         time.Time `json:"time"`
+	Client       string    `json:"client"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	Stream       bool      `json:"stream"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	DurationMS   int64     `json:"duration_ms"`
+	Error        string    `json:"error,omitempty"`
+}
+
+func newProxyServer(c config.Config) *proxyServer {
+	s := &proxyServer{
+		cfg:     c,
+		mux:     http.NewServeMux(),
+		logPath: filepath.Join(config.DataDir(), "serve-usage.jsonl"),
+		totals:  map[string]*clientUsage{},
+	}
+	s.mux.HandleFunc("POST /v1/chat/completions", s.chatCompletions)
+	s.mux.HandleFunc("GET /v1/models", s.models)
+	return s
+}
+
+func (s *proxyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Without client tokens, only local callers are trusted. A web page
+	// can still reach a loopback address, through DNS rebinding or with
+	// a form post, so check the Host header and require a JSON body,
+	// which browsers only send cross-origin after a CORS preflight.
+	if len(s.cfg.Serve.Clients) == 0 {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !isLoopback(host) {
+			writeOpenAIError(w, http.StatusForbidden, "invalid_request_error", "host "+r.Host+" is not allowed (set serve.clients in the ask config to accept remote clients)")
+			return
+		}
+	}
+	if r.Method == http.MethodPost {
+		if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+			writeOpenAIError(w, http.StatusUnsupportedMediaType, "invalid_request_error", "Content-Type must be application/json")
+			return
+		}
+	}
+	client, ok := s.client(r)
+	if !ok {
+		writeOpenAIError(w, http.StatusUnauthorized, "invalid_api_key", "unknown API key (set serve.clients in the ask config)")
+		return
+	}
+	s.mux.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+}
+
+// isLoopback reports whether host names this machine: localhost or a
+// loopback IP address, possibly in brackets.
+func isLoopback(host string) bool {
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// clientKey is the request context key for the client name.
+type clientKey struct{}
+
+// client names the caller: the serve.clients entry matching its bearer
+// token, or its remote host when no clients are configured.
+func (s *proxyServer) client(r *http.Request) (string, bool) {
+	if len(s.cfg.Serve.Clients) == 0 {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		if ua := strings.Fields(r.UserAgent()); len(ua) > 0 {
+			return host + " " + ua[0], true
+		}
+		return host, true
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	for _, name := range sortedKeys(s.cfg.Serve.Clients) {
+		want := os.ExpandEnv(s.cfg.Serve.Clients[name])
+		if want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// chatRequest is the subset of the chat completions request ask handles.
+type chatRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+	Stream        bool `json:"stream"`
+	StreamOptions struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+// messageText returns the text of a string or content-part array.
+func messageText(content json.RawMessage) (string, error) {
+	var s string
+	if json.Unmarshal(content, &s) == nil {
+		return s, nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return "", fmt.Errorf("content must be a string or an array of parts")
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type != "text" {
+			return "", fmt.Errorf("content part type %q is not supported", p.Type)
+		}
+		texts = append(texts, p.Text)
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// providerRequest converts an OpenAI request. System and developer
+// messages become the system prompt.
+func (s *proxyServer) providerRequest(in chatRequest) (ask.Provider, ask.Request, error) {
+	if in.Model == "" {
+		return nil, ask.Request{}, fmt.Errorf("model is required")
+	}
+	target, err := parseTarget(in.Model, s.cfg.ResolvedProvider())
+	if err != nil {
+		return nil, ask.Request{}, err
+	}
+	p, err := ask.Get(target.Provider)
+	if err != nil {
+		return nil, ask.Request{}, err
+	}
+	pcfg := s.cfg.ForProvider(p.Name())
+	apiKey, err := pcfg.RequireAPIKey(p)
+	if err != nil {
+		return nil, ask.Request{}, err
+	}
+
+	req := ask.Request{Model: target.Model, APIKey: apiKey, BaseURL: pcfg.BaseURL}
+	var system []string
+	for _, m := range in.Messages {
+		text, err := messageText(m.Content)
+		if err != nil {
+			return nil, ask.Request{}, err
+		}
+		switch m.Role {
+		case "system", "developer":
+			system = append(system, text)
+		case "user", "assistant":
+			req.Messages = append(req.Messages, ask.Message{Role: m.Role, Content: text})
+		default:
+			return nil, ask.Request{}, fmt.Errorf("message role %q is not supported", m.Role)
+		}
+	}
+	if len(req.Messages) == 0 {
+		return nil, ask.Request{}, fmt.Errorf("messages must include a user message")
+	}
+	req.System = strings.Join(system, "\n\n")
+	return p, req, nil
+}
+
+func (s *proxyServer) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	var in chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "invalid JSON: "+err.Error())
+		return
+	}
+	p, req, err := s.providerRequest(in)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	start := time.Now()
+	id := fmt.Sprintf("chatcmpl-ask%d", start.UnixNano())
+	modelID := p.ResolveModel(req.Model)
+	var res ask.Result
+	if in.Stream {
+		res, err = s.stream(w, r.Context(), p, req, id, in.Model, in.StreamOptions.IncludeUsage)
+	} else {
+		res, err = ask.Collect(p.Run(r.Context(), req), nil)
+		if err != nil {
+			writeOpenAIError(w, http.StatusBadGateway, "upstream_error", err.Error())
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"id":      id,
+				"object":  "chat.completion",
+				"created": start.Unix(),
+				"model":   in.Model,
+				"choices": []any{map[string]any{
+					"index":         0,
+					"message":       map[string]any{"role": "assistant", "content": res.Text + ask.Footnotes(res.Sources)},
+					"finish_reason": openAIFinishReason(res.StopReason),
+				}},
+				"usage": openAIUsage(res.Usage),
+			})
+		}
+	}
+
+	rec := usageRecord{
+		Time: start, Client: r.Context().Value(clientKey{}).(string), Provider: p.Name(), Model: modelID, Stream: in.Stream,
+		InputTokens: res.Usage.InputTokens, OutputTokens: res.Usage.OutputTokens, DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	s.logUsage(rec)
+}
+
+// stream writes the response as chat.completion.chunk server-sent events.
+// An error before the first chunk gets a normal error response; later ones
+// are sent as an error event.
+func (s *proxyServer) stream(w http.ResponseWriter, ctx context.Context, p ask.Provider, req ask.Request, id, model string, includeUsage bool) (ask.Result, error) {
+	flusher, _ := w.(http.Flusher)
+	created := time.Now().Unix()
+	started := false
+	send := func(v any) {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			started = true
+		}
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	chunk := func(delta map[string]any, finish any) map[string]any {
+		return map[string]any{
+			"id": id, "object": "chat.completion.chunk", "created": created, "model": model,
+			"choices": []any{map[string]any{"index": 0, "delta": delta, "finish_reason": finish}},
+		}
+	}
+
+	res, err := ask.Collect(p.Run(ctx, req), func(t string) {
+		if !started {
+			send(chunk(map[string]any{"role": "assistant", "content": ""}, nil))
+		}
+		send(chunk(map[string]any{"content": t}, nil))
+	})
+	if err != nil {
+		if !started {
+			writeOpenAIError(w, http.StatusBadGateway, "upstream_error", err.Error())
+		} else {
+			send(map[string]any{"error": map[string]any{"message": err.Error(), "type": "upstream_error"}})
+		}
+		return res, err
+	}
+	if !started {
+		send(chunk(map[string]any{"role": "assistant", "content": ""}, nil))
+	}
+	if notes := ask.Footnotes(res.Sources); notes != "" {
+		send(chunk(map[string]any{"content": notes}, nil))
+	}
+	send(chunk(map[string]any{}, openAIFinishReason(res.StopReason)))
+	if includeUsage {
+		send(map[string]any{
+			"id": id, "object": "chat.completion.chunk", "created": created, "model": model,
+			"choices": []any{}, "usage": openAIUsage(res.Usage),
+		})
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	return res, nil
+}
+
+// openAIFinishReason maps a provider's stop reason to the finish_reason
+// values OpenAI clients expect.
+func openAIFinishReason(stop string) string {
+	switch stop {
+	case "max_tokens", "length", "MAX_TOKENS", "max_output_tokens":
+		return "length"
+	case "tool_use", "tool_calls":
+		return "tool_calls"
+	case "content_filter", "refusal", "SAFETY", "RECITATION", "PROHIBITED_CONTENT":
+		return "content_filter"
+	}
+	return "stop"
+}
+
+func openAIUsage(u ask.Usage) map[string]any {
+	return map[string]any{
+		"prompt_tokens":     u.InputTokens,
+		"completion_tokens": u.OutputTokens,
+		"total_tokens":      u.InputTokens + u.OutputTokens,
+	}
+}
+
+// models lists provider:alias IDs for every provider with credentials.
+func (s *proxyServer) models(w http.ResponseWriter, r *http.Request) {
+	data := []any{}
+	for _, name := range ask.Names() {
+		p, _ := ask.Lookup(name)
+		if _, err := s.cfg.ForProvider(name).RequireAPIKey(p); err != nil {
+			continue
+		}
+		for _, alias := range p.ModelAliases() {
+			data = append(data, map[string]any{"id": name + ":" + alias, "object": "model", "created": 0, "owned_by": name})
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"object": "list", "data": data})
+}
+
+func writeOpenAIError(w http.ResponseWriter, status int, typ, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": message, "type": typ}})
+}
+
+// logUsage prints rec to stderr, appends it to the usage log and adds it
+// to the client's totals.
+func (s *proxyServer) logUsage(rec usageRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := fmt.Sprintf("%d in / %d out", rec.InputTokens, rec.OutputTokens)
+	if rec.Error != "" {
+		status = "error: " + firstLine(rec.Error, 80)
+	}
+	fmt.Fprintf(os.Stderr, "%s %s → %s:%s %s (%.1fs)\n",
+		rec.Time.Format("15:04:05"), rec.Client, rec.Provider, rec.Model, status, float64(rec.DurationMS)/1000)
+
+	t := s.totals[rec.Client]
+	if t == nil {
+		t = &clientUsage{}
+		s.totals[rec.Client] = t
+	}
+	t.Requests++
+	t.InputTokens += rec.InputTokens
+	t.OutputTokens += rec.OutputTokens
+
+	if err := os.MkdirAll(filepath.Dir(s.logPath), 0755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(s.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	json.NewEncoder(f).Encode(rec)
+}
+
+func (s *proxyServer) printTotals() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.totals) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nCLIENT\tREQUESTS\tINPUT\tOUTPUT")
+	for _, name := range sortedKeys(s.totals) {
+		t := s.totals[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", name, t.Requests, t.InputTokens, t.OutputTokens)
+	}
+	tw.Flush()
+}
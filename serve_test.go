@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/laurensent/ask/pkg/config"
+)
+
+func TestProxyServer(t *testing.T) {
+	var upstream []map[string]any
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		upstream = append(upstream, body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"content":"Hel"}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"content":"lo"}}],"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer up.Close()
+
+	s := newProxyServer(config.Config{Provider: "xai", APIKey: "key", BaseURL: up.URL, Serve: config.Serve{Clients: map[string]string{"editor": "secret"}}})
+	s.logPath = filepath.Join(t.TempDir(), "usage.jsonl")
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	post := func(token, body string) (*http.Response, string) {
+		req, _ := http.NewRequest("POST", srv.URL+"/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+
+	if resp, _ := post("wrong", `{}`); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d, want 401", resp.StatusCode)
+	}
+
+	resp, body := post("secret", `{"model":"xai:grok-3-mini","messages":[{"role":"system","content":"Be brief."},{"role":"user","content":[{"type":"text","text":"hi"}]}]}`)
+	var out struct {
+		Model   string
+		Choices []struct{ Message struct{ Content string } }
+		Usage   struct {
+			TotalTokens int64 `json:"total_tokens"`
+		}
+	}
+	json.Unmarshal([]byte(body), &out)
+	if resp.StatusCode != 200 || len(out.Choices) != 1 || out.Choices[0].Message.Content != "Hello" || out.Usage.TotalTokens != 9 || out.Model != "xai:grok-3-mini" {
+		t.Errorf("completion: status %d, body %s", resp.StatusCode, body)
+	}
+	if got := upstream[0]["model"]; got != "grok-3-mini" {
+		t.Errorf("upstream model = %v", got)
+	}
+	if msgs, _ := upstream[0]["messages"].([]any); len(msgs) != 2 {
+		t.Errorf("upstream messages = %v, want system and user", upstream[0]["messages"])
+	}
+
+	resp, body = post("secret", `{"model":"grok-3-mini","stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi"}]}`)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("stream Content-Type = %q", ct)
+	}
+	for _, want := range []string{`"delta":{"content":"Hel"}`, `"finish_reason":"stop"`, `"total_tokens":9`, "data: [DONE]"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stream is missing %s:\n%s", want, body)
+		}
+	}
+
+	if resp, body := post("secret", `{"model":"nope:x","messages":[{"role":"tool","content":"x"}]}`); resp.StatusCode != 400 || !strings.Contains(body, "not supported") {
+		t.Errorf("tool role: status %d, body %s", resp.StatusCode, body)
+	}
+
+	req, _ := http.NewRequest("GET", srv.URL+"/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(data), `"id":"xai:`) {
+			t.Errorf("models = %s", data)
+		}
+	}
+
+	s.mu.Lock()
+	if u := s.totals["editor"]; u == nil || u.Requests != 2 || u.InputTokens != 14 {
+		t.Errorf("editor usage = %+v", u)
+	}
+	s.mu.Unlock()
+}
+
+func TestProxyServerLocalOnly(t *testing.T) {
+	s := newProxyServer(config.Config{Provider: "xai", APIKey: "key"})
+	s.logPath = filepath.Join(t.TempDir(), "usage.jsonl")
+	tests := []struct {
+		host, contentType string
+		want              int
+	}{
+		{"127.0.0.1:8088", "application/json", http.StatusBadRequest}, // reaches the handler
+		{"localhost:8088", "application/json; charset=utf-8", http.StatusBadRequest},
+		{"[::1]:8088", "application/json", http.StatusBadRequest},
+		{"attacker.example:8088", "application/json", http.StatusForbidden},
+		{"127.0.0.1:8088", "text/plain", http.StatusUnsupportedMediaType},
+		{"127.0.0.1:8088", "", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{}`))
+		req.Host = tt.host
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("host %s, Content-Type %q: status %d, want %d", tt.host, tt.contentType, w.Code, tt.want)
+		}
+	}
+
+	cfg = config.Config{}
+	if err := runServe("0.0.0.0:0"); err == nil || !strings.Contains(err.Error(), "serve.clients") {
+		t.Errorf("runServe on all interfaces: %v", err)
+	}
+}
+
+func TestOpenAIFinishReason(t *testing.T) {
+	tests := map[string]string{
+		"end_turn":          "stop",
+		"":                  "stop",
+		"max_tokens":        "length",
+		"MAX_TOKENS":        "length",
+		"max_output_tokens": "length",
+		"length":            "length",
+		"tool_use":          "tool_calls",
+		"SAFETY":            "content_filter",
+	}
+	for stop, want := range tests {
+		if got := openAIFinishReason(stop); got != want {
+			t.Errorf("openAIFinishReason(%q) = %q, want %q", stop, got, want)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+const (
+	maxToolOutput  = 32 * 1024
+	maxReadFile    = 100 * 1024
+	maxGrepMatches = 200
+	maxDirEntries  = 500
+	commandTimeout = 30 * time.Second
+)
+
+// defaultAllowedCommands are the commands run_command accepts unless the
+// agent config sets its own list. An entry matches a command line that
+// starts with the same words.
+var defaultAllowedCommands = []string{
+	"ls", "wc", "du", "file", "head", "tail", "stat",
+	"git status", "git log", "git diff", "git show", "git branch",
+	"go version", "go list",
+}
+
+// deniedFlags are flags refused under a command prefix because they write
+// files or persistent settings, whatever the allow-list says.
+var deniedFlags = map[string][]string{
+	"git":    {"--output"},
+	"go env": {"-w", "-u"},
+}
+
+// agentTools returns the tools available in root.
+func agentTools(root string, allowed []string) []ask.Tool {
+	if allowed == nil {
+		allowed = defaultAllowedCommands
+	}
+	return []ask.Tool{
+		readFileTool{root},
+		listDirTool{root},
+		grepTool{root},
+		runCommandTool{root, allowed},
+	}
+}
+
+// objectSchema builds a JSON Schema object with the given properties.
+func objectSchema(props map[string]any, required ...string) map[string]any {
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func stringProp(desc string) map[string]any {
+	return map[string]any{"type": "string", "description": desc}
+}
+
+// sandboxPath resolves path against root and rejects anything outside it,
+// including escapes through symlinks.
+func sandboxPath(root, path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+	p := path
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	p = filepath.Clean(p)
+	if real, err := filepath.EvalSymlinks(p); err == nil {
+		p = real
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		realRoot = root
+	}
+	rel, err := filepath.Rel(realRoot, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the working directory", path)
+	}
+	return p, nil
+}
+
+// capOutput truncates s to n bytes with a note.
+func capOutput(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + fmt.Sprintf("\n[truncated: %d of %d bytes shown]", n, len(s))
+}
+
+type readFileTool struct{ root string }
+
+func (readFileTool) Name() string { return "read_file" }
+func (readFileTool) Description() string {
+	return "Read a text file in the working directory. Large files are truncated."
+}
+func (readFileTool) Parameters() map[string]any {
+	return objectSchema(map[string]any{
+		"path": stringProp("file path, relative to the working directory"),
+	}, "path")
+}
+
+func (t readFileTool) Run(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct{ Path string }
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	path, err := sandboxPath(t.root, in.Path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return "", fmt.Errorf("%s is a binary file (%d bytes)", in.Path, len(data))
+	}
+	return capOutput(string(data), maxReadFile), nil
+}
+
+type listDirTool struct{ root string }
+
+func (listDirTool) Name() string { return "list_dir" }
+func (listDirTool) Description() string {
+	return "List a directory in the working directory, with file sizes in bytes. Directories end in /."
+}
+func (listDirTool) Parameters() map[string]any {
+	return objectSchema(map[string]any{
+		"path": stringProp("directory path, relative to the working directory (default .)"),
+	})
+}
+
+func (t listDirTool) Run(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct{ Path string }
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	path, err := sandboxPath(t.root, in.Path)
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i, e := range entries {
+		if i == maxDirEntries {
+			fmt.Fprintf(&b, "[%d more entries]\n", len(entries)-i)
+			break
+		}
+		if e.IsDir() {
+			fmt.Fprintf(&b, "%s/\n", e.Name())
+			continue
+		}
+		var size int64
+		if info, err := e.Info(); err == nil {
+			size = info.Size()
+		}
+		fmt.Fprintf(&b, "%s\t%d\n", e.Name(), size)
+	}
+	if b.Len() == 0 {
+		return "(empty directory)", nil
+	}
+	return b.String(), nil
+}
+
+type grepTool struct{ root string }
+
+func (grepTool) Name() string { return "grep" }
+func (grepTool) Description() string {
+	return "Search files in the working directory for a regular expression (Go syntax). Returns file:line: text matches."
+}
+func (grepTool) Parameters() map[string]any {
+	return objectSchema(map[string]any{
+		"pattern": stringProp("regular expression to search for"),
+		"path":    stringProp("file or directory to search (default .)"),
+		"glob":    stringProp("only search files whose name matches this glob, e.g. *.go"),
+	}, "pattern")
+}
+
+func (t grepTool) Run(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct{ Pattern, Path, Glob string }
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	re, err := regexp.Compile(in.Pattern)
+	if err != nil {
+		return "", err
+	}
+	start, err := sandboxPath(t.root, in.Path)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	errFull := errors.New("full")
+	err = filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if path != start && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if in.Glob != "" {
+			if ok, _ := filepath.Match(in.Glob, d.Name()); !ok {
+				return nil
+			}
+		}
+		// WalkDir doesn't follow symlinks, but opening one does.
+		real, err := sandboxPath(t.root, path)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(t.root, path)
+		if grepFile(real, rel, re, &matches) {
+			return errFull
+		}
+		return nil
+	})
+	if err != nil && err != errFull {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "no matches", nil
+	}
+	out := strings.Join(matches, "\n")
+	if err == errFull {
+		out += fmt.Sprintf("\n[stopped after %d matches]", maxGrepMatches)
+	}
+	return capOutput(out, maxToolOutput), nil
+}
+
+// grepFile appends the matching lines of a text file to matches and
+// reports whether the match limit was reached.
+func grepFile(path, name string, re *regexp.Regexp, matches *[]string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.IndexByte(line, 0) >= 0 {
+			return false // binary
+		}
+		if re.MatchString(line) {
+			*matches = append(*matches, fmt.Sprintf("%s:%d: %s", name, n, firstLine(line, 200)))
+			if len(*matches) >= maxGrepMatches {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type runCommandTool struct {
+	root    string
+	allowed []string
+}
+
+func (runCommandTool) Name() string { return "run_command" }
+func (t runCommandTool) Description() string {
+	return "Run an allow-listed command in the working directory, without a shell. Path arguments must stay inside the working directory. Allowed: " + strings.Join(t.allowed, ", ") + "."
+}
+func (runCommandTool) Parameters() map[string]any {
+	return objectSchema(map[string]any{
+		"command": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": `program and arguments, e.g. ["git", "log", "-n", "5"]`,
+		},
+	}, "command")
+}
+
+// permits reports whether argv starts with one of the allowed commands.
+func (t runCommandTool) permits(argv []string) bool {
+	for _, a := range t.allowed {
+		if hasPrefixWords(argv, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkArgs rejects arguments that reach outside the root, such as
+// absolute or ../ paths (also as --flag=value or -Xvalue), and denied flags.
+func (t runCommandTool) checkArgs(argv []string) error {
+	for _, arg := range argv[1:] {
+		for prefix, flags := range deniedFlags {
+			for _, f := range flags {
+				if hasPrefixWords(argv, prefix) && (arg == f || strings.HasPrefix(arg, f+"=")) {
+					return fmt.Errorf("%s is not allowed with %s", f, prefix)
+				}
+			}
+		}
+		values := []string{arg}
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			_, v, ok := strings.Cut(arg, "=")
+			if !ok {
+				continue
+			}
+			values = []string{v}
+		case strings.HasPrefix(arg, "-"):
+			// A short option may carry its value glued on (-C/etc), possibly
+			// after other flags (-xf../a); check every possible start.
+			values = nil
+			for i := 2; i < len(arg); i++ {
+				values = append(values, arg[i:])
+			}
+		}
+		for _, v := range values {
+			if _, err := sandboxPath(t.root, v); err != nil {
+				return fmt.Errorf("argument %q: %w", arg, err)
+			}
+		}
+	}
+	return nil
+}
+
+// hasPrefixWords reports whether argv starts with the words of command.
+func hasPrefixWords(argv []string, command string) bool {
+	words := strings.Fields(command)
+	return len(words) > 0 && len(argv) >= len(words) && slices.Equal(argv[:len(words)], words)
+}
+
+func (t runCommandTool) Run(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct{ Command []string }
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if len(in.Command) == 0 {
+		return "", fmt.Errorf("empty command")
+	}
+	if !t.permits(in.Command) {
+		allowed := append([]string(nil), t.allowed...)
+		sort.Strings(allowed)
+		return "", fmt.Errorf("%s is not allowed (allowed: %s)", in.Command[0], strings.Join(allowed, ", "))
+	}
+	if err := t.checkArgs(in.Command); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, in.Command[0], in.Command[1:]...)
+	cmd.Dir = t.root
+	out, err := cmd.CombinedOutput()
+	result := capOutput(string(out), maxToolOutput)
+	if err != nil {
+		return result + "\n[" + err.Error() + "]", nil
+	}
+	if result == "" {
+		return "(no output)", nil
+	}
+	return result, nil
+}
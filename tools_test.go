@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/laurensent/ask/pkg/ask"
+)
+
+func TestSandboxPath(t *testing.T) {
+	root := t.TempDir()
+	root, _ = filepath.EvalSymlinks(root)
+	os.Mkdir(filepath.Join(root, "sub"), 0755)
+	os.Symlink(os.TempDir(), filepath.Join(root, "escape"))
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"", true},
+		{"sub", true},
+		{"sub/../sub/file.go", true},
+		{filepath.Join(root, "sub"), true},
+		{"..", false},
+		{"../other", false},
+		{"/etc/passwd", false},
+		{"escape", false},
+	}
+	for _, tt := range tests {
+		_, err := sandboxPath(root, tt.path)
+		if (err == nil) != tt.ok {
+			t.Errorf("sandboxPath(%q) err = %v, want ok=%v", tt.path, err, tt.ok)
+		}
+	}
+}
+
+func TestRunCommandPermits(t *testing.T) {
+	tool := runCommandTool{allowed: []string{"ls", "git log"}}
+	tests := []struct {
+		argv []string
+		want bool
+	}{
+		{[]string{"ls", "-la"}, true},
+		{[]string{"git", "log", "-n", "5"}, true},
+		{[]string{"git", "push"}, false},
+		{[]string{"git"}, false},
+		{[]string{"rm", "-rf", "."}, false},
+	}
+	for _, tt := range tests {
+		if got := tool.permits(tt.argv); got != tt.want {
+			t.Errorf("permits(%q) = %v, want %v", tt.argv, got, tt.want)
+		}
+	}
+}
+
+func TestRunCommandCheckArgs(t *testing.T) {
+	root := t.TempDir()
+	os.Symlink("/etc", filepath.Join(root, "etc"))
+	tool := runCommandTool{root: root, allowed: defaultAllowedCommands}
+	tests := []struct {
+		argv    []string
+		wantErr bool
+	}{
+		{[]string{"head", "-n", "5", "main.go"}, false},
+		{[]string{"git", "log", "--format=%h %s", "main..feature"}, false},
+		{[]string{"head", "/etc/passwd"}, true},
+		{[]string{"tail", "../secret"}, true},
+		{[]string{"head", "etc/passwd"}, true},
+		{[]string{"git", "diff", "--output=/tmp/x"}, true},
+		{[]string{"git", "diff", "--output", "x"}, true},
+		{[]string{"git", "log", "--no-index=../x"}, true},
+		{[]string{"go", "env", "-w", "GOFLAGS=-x"}, true},
+		{[]string{"head", "-n5", "main.go"}, false},
+		{[]string{"file", "-f/etc/passwd"}, true},
+		{[]string{"git", "log", "-C/etc"}, true},
+		{[]string{"tail", "-n5../secret"}, true},
+	}
+	for _, tt := range tests {
+		if err := tool.checkArgs(tt.argv); (err != nil) != tt.wantErr {
+			t.Errorf("checkArgs(%q) = %v, want error %v", tt.argv, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFileTools(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.Mkdir(filepath.Join(root, "pkg"), 0755)
+	os.WriteFile(filepath.Join(root, "pkg", "util.go"), []byte("package pkg\n\nfunc Helper() {}\n"), 0644)
+
+	tests := []struct {
+		tool ask.Tool
+		args string
+		want string
+	}{
+		{readFileTool{root}, `{"path":"main.go"}`, "func main() {}"},
+		{listDirTool{root}, `{}`, "main.go\t29\npkg/\n"},
+		{grepTool{root}, `{"pattern":"^func \\w+","glob":"*.go"}`, "main.go:3: func main() {}\npkg/util.go:3: func Helper() {}"},
+		{grepTool{root}, `{"pattern":"nothing"}`, "no matches"},
+	}
+	for _, tt := range tests {
+		got, err := tt.tool.Run(context.Background(), json.RawMessage(tt.args))
+		if err != nil {
+			t.Errorf("%s(%s): %v", tt.tool.Name(), tt.args, err)
+			continue
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s(%s) = %q, want %q", tt.tool.Name(), tt.args, got, tt.want)
+		}
+	}
+
+	if _, err := (readFileTool{root}).Run(context.Background(), json.RawMessage(`{"path":"../x"}`)); err == nil {
+		t.Error("read_file outside the root should fail")
+	}
+
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("func leaked() {}\n"), 0644)
+	os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.go"))
+	if got, _ := (grepTool{root}).Run(context.Background(), json.RawMessage(`{"pattern":"leaked"}`)); got != "no matches" {
+		t.Errorf("grep followed a symlink out of the root: %q", got)
+	}
+}